@@ -0,0 +1,95 @@
+package overcurrent
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// invocation tracks a single invocation of a breaker function so that
+// the function can be accounted for if the breaker stops waiting for it
+// (e.g. on timeout or cancellation) before it returns.
+type invocation struct {
+	status      int32
+	abandonedAt time.Time
+}
+
+const (
+	invocationRunning int32 = iota
+	invocationCompleted
+	invocationAbandoned
+	invocationQueued
+	invocationSkipped
+)
+
+// invoke calls the given function with the breaker's invocation timeout.
+// If the function is still running when this method returns, it is counted
+// as abandoned until the function eventually returns. If a pool slot is
+// given, the function is run on a worker of the breaker's pool. A function
+// which is still queued when this method returns is never run, and is not
+// counted as abandoned.
+func (cb *circuitBreaker) invoke(ctx context.Context, f BreakerFunc, slot *poolSlot) error {
+	if cb.panicRecovery {
+		f = recoverPanics(f)
+	}
+
+	var (
+		inv     = &invocation{}
+		timeout = cb.currentInvocationTimeout()
+		start   = cb.clock.Now()
+	)
+
+	var spawn func(context.Context, func() error) <-chan error
+	if slot != nil {
+		inv.status = invocationQueued
+		spawn = slot.spawn
+	}
+
+	err := spawnWithTimeout(ctx, func(ctx context.Context) error {
+		if slot != nil && !atomic.CompareAndSwapInt32(&inv.status, invocationQueued, invocationRunning) {
+			return ctx.Err()
+		}
+
+		defer func() {
+			if !atomic.CompareAndSwapInt32(&inv.status, invocationRunning, invocationCompleted) {
+				cb.completeAbandoned(inv)
+			}
+		}()
+
+		return f(ctx)
+	}, cb.clock, timeout, spawn)
+
+	// Written before the swap so that the function's goroutine can
+	// read it safely once it observes the abandoned status.
+	inv.abandonedAt = cb.clock.Now()
+
+	if atomic.CompareAndSwapInt32(&inv.status, invocationRunning, invocationAbandoned) {
+		atomic.AddInt64(&cb.abandoned, 1)
+		cb.collector.ReportCount(EventTypeAbandoned)
+	} else {
+		atomic.CompareAndSwapInt32(&inv.status, invocationQueued, invocationSkipped)
+	}
+
+	if err == ErrInvocationTimeout {
+		// The caller's deadline may have been sooner than the invocation timeout
+		if deadline, ok := ctx.Deadline(); ok && (timeout == 0 || deadline.Sub(start) < timeout) {
+			timeout = deadline.Sub(start)
+		}
+
+		return &TimeoutError{Name: cb.name, Timeout: timeout}
+	}
+
+	return err
+}
+
+func (cb *circuitBreaker) completeAbandoned(inv *invocation) {
+	atomic.AddInt64(&cb.abandoned, -1)
+	cb.collector.ReportCount(EventTypeAbandonedCompleted)
+	cb.collector.ReportDuration(EventTypeAbandonedDuration, cb.clock.Now().Sub(inv.abandonedAt))
+}
+
+// tooManyAbandoned returns true if the number of abandoned invocations
+// which are still running has reached the configured maximum.
+func (cb *circuitBreaker) tooManyAbandoned() bool {
+	return cb.maxAbandoned > 0 && atomic.LoadInt64(&cb.abandoned) >= int64(cb.maxAbandoned)
+}
@@ -0,0 +1,97 @@
+package overcurrent
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// adaptiveTimeout derives an invocation timeout from the durations of recent
+// successful calls. Until the sample window fills, the maximum timeout is used.
+type adaptiveTimeout struct {
+	mutex      sync.Mutex
+	samples    []time.Duration
+	next       int
+	filled     bool
+	percentile float64
+	multiplier float64
+	min        time.Duration
+	max        time.Duration
+	timeout    time.Duration
+}
+
+func newAdaptiveTimeout(window int, percentile, multiplier float64, min, max time.Duration) *adaptiveTimeout {
+	if window < 1 {
+		window = 1
+	}
+
+	return &adaptiveTimeout{
+		samples:    make([]time.Duration, window),
+		percentile: percentile,
+		multiplier: multiplier,
+		min:        min,
+		max:        max,
+		timeout:    max,
+	}
+}
+
+// current returns the current timeout.
+func (a *adaptiveTimeout) current() time.Duration {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	return a.timeout
+}
+
+// observe records the duration of a successful call and returns the new timeout
+// along with a flag indicating whether or not the timeout has changed.
+func (a *adaptiveTimeout) observe(duration time.Duration) (time.Duration, bool) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.samples[a.next] = duration
+	a.next = (a.next + 1) % len(a.samples)
+
+	if a.next == 0 {
+		a.filled = true
+	}
+
+	if !a.filled {
+		return a.timeout, false
+	}
+
+	timeout := time.Duration(float64(percentile(a.samples, a.percentile)) * a.multiplier)
+	if timeout < a.min {
+		timeout = a.min
+	}
+
+	if timeout > a.max {
+		timeout = a.max
+	}
+
+	if timeout == a.timeout {
+		return timeout, false
+	}
+
+	a.timeout = timeout
+	return timeout, true
+}
+
+// percentile returns the smallest value which is at least as large as the given
+// fraction of the values.
+func percentile(values []time.Duration, p float64) time.Duration {
+	sorted := append([]time.Duration{}, values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	index := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if index < 0 {
+		index = 0
+	}
+
+	if index >= len(sorted) {
+		index = len(sorted) - 1
+	}
+
+	return sorted[index]
+}
@@ -0,0 +1,57 @@
+package overcurrent
+
+import (
+	"time"
+
+	"github.com/aphistic/sweet"
+	. "github.com/onsi/gomega"
+)
+
+type AdaptiveSuite struct{}
+
+func (s *AdaptiveSuite) TestObserve(t sweet.T) {
+	a := newAdaptiveTimeout(4, 0.5, 2, time.Millisecond, time.Second)
+	Expect(a.current()).To(Equal(time.Second))
+
+	for _, duration := range []time.Duration{10, 40, 30} {
+		_, changed := a.observe(duration * time.Millisecond)
+		Expect(changed).To(BeFalse())
+	}
+
+	Expect(a.current()).To(Equal(time.Second))
+
+	timeout, changed := a.observe(20 * time.Millisecond)
+	Expect(timeout).To(Equal(40 * time.Millisecond))
+	Expect(changed).To(BeTrue())
+
+	// Replaces the oldest sample
+	timeout, changed = a.observe(50 * time.Millisecond)
+	Expect(timeout).To(Equal(60 * time.Millisecond))
+	Expect(changed).To(BeTrue())
+
+	_, changed = a.observe(30 * time.Millisecond)
+	Expect(changed).To(BeFalse())
+	Expect(a.current()).To(Equal(60 * time.Millisecond))
+}
+
+func (s *AdaptiveSuite) TestObserveClamped(t sweet.T) {
+	a := newAdaptiveTimeout(2, 1, 2, 50*time.Millisecond, 100*time.Millisecond)
+
+	a.observe(time.Millisecond)
+	timeout, _ := a.observe(time.Millisecond)
+	Expect(timeout).To(Equal(50 * time.Millisecond))
+
+	a.observe(time.Second)
+	timeout, _ = a.observe(time.Second)
+	Expect(timeout).To(Equal(100 * time.Millisecond))
+}
+
+func (s *AdaptiveSuite) TestPercentile(t sweet.T) {
+	values := []time.Duration{5, 1, 4, 2, 3}
+
+	Expect(percentile(values, 0)).To(Equal(time.Duration(1)))
+	Expect(percentile(values, 0.5)).To(Equal(time.Duration(3)))
+	Expect(percentile(values, 0.99)).To(Equal(time.Duration(5)))
+	Expect(percentile(values, 1)).To(Equal(time.Duration(5)))
+	Expect(values).To(Equal([]time.Duration{5, 1, 4, 2, 3}))
+}
@@ -2,9 +2,11 @@ package overcurrent
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/rand"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/efritz/backoff"
@@ -25,43 +27,107 @@ type (
 
 		// ShouldTry returns true if the circuit breaker is closed or half-closed with
 		// some probability. Successive calls to this method may yield different results
-		// depending on the registered trip condition.
+		// depending on the registered trip condition. A closed breaker with adaptive
+		// throttling may also return false with some probability.
 		ShouldTry() bool
 
+		// State returns the current state of the circuit breaker. Unlike ShouldTry, this
+		// method does not cause any state transitions.
+		State() CircuitState
+
+		// Snapshot returns a point-in-time view of the circuit breaker's state. Like State,
+		// this method does not cause any state transitions.
+		Snapshot() Snapshot
+
 		// MarkResult takes the result of the protected section and marks it as a success if
 		// the error is nil or if the failure interpreter decides not to trip on this error.
+		// A context.Canceled error is caused by the caller giving up and is marked as neither
+		// a success nor a failure. While the breaker is half-closed with a bounded number of
+		// probes, results given to this method are counted as probe results; Call tells the
+		// results of probes apart from those of calls admitted before the breaker opened.
 		MarkResult(err error) bool
 
 		// Call attempts to call the given function if the circuit breaker is closed, or if
 		// the circuit breaker is half-closed (with some probability). Otherwise, return an
 		// ErrCircuitOpen. If the function times out, the circuit breaker will fail with an
 		// ErrInvocationTimeout. If the function is invoked and yields a value before the
-		// timeout elapses, that value is returned.
+		// timeout elapses, that value is returned. The context passed to the function has
+		// a deadline set to the invocation timeout. If the function panics, the panic is
+		// recovered and returned as a PanicError (unless disabled). If a retry policy is
+		// configured, failed calls may be attempted again before an error is returned.
 		Call(f BreakerFunc) error
 
+		// CallContext behaves like Call, but the context passed to the function is
+		// derived from the given context. Cancellation of the given context will be
+		// observed by the function, and Call will return the context's error without
+		// waiting for the function to complete. Errors which occur after the given
+		// context is canceled are not counted as failures against the breaker. If the
+		// deadline of the given context elapses first, the call counts as a timeout.
+		CallContext(ctx context.Context, f BreakerFunc) error
+
 		// CallAsync invokes the given function in a goroutine, returning a channel which
 		// may receive one non-nil error value and then close. The channel will close without
 		// writing a value on success.
 		CallAsync(f BreakerFunc) <-chan error
+
+		// Close stops the workers of the breaker's thread pool, if any, once the calls
+		// already queued on the pool have run. Later calls are rejected. Breakers without
+		// a thread pool hold no resources, so closing them is optional.
+		Close() error
 	}
 
 	BreakerConfigFunc func(*circuitBreaker)
 	BreakerFunc       func(ctx context.Context) error
 
 	circuitBreaker struct {
+		name                       string
 		invocationTimeout          time.Duration
+		adaptiveTimeout            *adaptiveTimeout
 		halfClosedRetryProbability float64
+		halfClosedMaxProbes        int
+		halfClosedSuccessThreshold int
 		maxConcurrency             int
 		maxConcurrencyTimeout      time.Duration
+		concurrencyLimiter         ConcurrencyLimiter
+		rateLimit                  float64
+		rateLimitBurst             int
+		rateLimitTimeout           time.Duration
+		rateLimiter                *tokenBucket
+		fallbackTimeout            time.Duration
+		fallbackMaxConcurrency     int
+		poolCoreSize               int
+		poolQueueSize              int
+		poolRejectionThreshold     int
+		pool                       *workerPool
 		resetBackoff               backoff.Backoff
+		minRetryAfter              time.Duration
+		maxRetryAfter              time.Duration
 		failureInterpreter         FailureInterpreter
+		retryBackoff               backoff.Backoff
+		retryMaxAttempts           int
+		retryInterpreter           FailureInterpreter
+		retryBudget                *RetryBudget
+		hedging                    *hedgePolicy
+		throttleK                  float64
+		throttleWindow             time.Duration
+		throttle                   *adaptiveThrottle
 		tripCondition              TripCondition
 		collector                  MetricCollector
+		panicRecovery              bool
+		maxAbandoned               int
+		dryRun                     bool
 		clock                      glock.Clock
+		listeners                  []StateChangeListener
 		mutex                      sync.RWMutex
 		state                      CircuitState
+		changes                    []StateChange
 		lastFailureTime            *time.Time
 		resetTimeout               *time.Duration
+		retryAfter                 *time.Duration
+		activeProbes               int
+		abandoned                  int64
+		probeSuccesses             int
+		probeGeneration            uint64
 	}
 
 	CircuitState int
@@ -76,11 +142,23 @@ const (
 )
 
 var (
-	// ErrCircuitOpen occurs when the Call method fails immediately.
+	// ErrCircuitOpen occurs when the Call method fails immediately. The error returned
+	// by the breaker is a *CircuitOpenError which matches this value via errors.Is.
 	ErrCircuitOpen = fmt.Errorf("circuit is open")
 
-	// ErrInvocationTimeout occurs when the method takes too long to execute.
-	ErrInvocationTimeout = fmt.Errorf("invocation has timed out")
+	// ErrInvocationTimeout occurs when the method takes too long to execute. This
+	// error wraps context.DeadlineExceeded. The error returned by the breaker is a
+	// *TimeoutError which matches this value via errors.Is.
+	ErrInvocationTimeout = fmt.Errorf("invocation has timed out: %w", context.DeadlineExceeded)
+
+	// ErrRetryBudgetExhausted occurs when a failed call is not retried because the
+	// retry budget is exhausted. The error returned by the breaker is a *RetryBudgetError
+	// which matches this value via errors.Is.
+	ErrRetryBudgetExhausted = fmt.Errorf("retry budget exhausted")
+
+	// ErrMaxAbandoned occurs when the Call method fails immediately because too many
+	// previous invocations have timed out and are still running.
+	ErrMaxAbandoned = fmt.Errorf("too many abandoned invocations")
 )
 
 // NewCircuitBreaker creates a new CircuitBreaker.
@@ -98,6 +176,7 @@ func newCircuitBreaker(configs ...BreakerConfigFunc) *circuitBreaker {
 		failureInterpreter:         NewAnyErrorFailureInterpreter(),
 		tripCondition:              NewConsecutiveFailureTripCondition(5),
 		collector:                  defaultCollector,
+		panicRecovery:              true,
 		clock:                      glock.NewRealClock(),
 	}
 
@@ -105,30 +184,158 @@ func newCircuitBreaker(configs ...BreakerConfigFunc) *circuitBreaker {
 		config(breaker)
 	}
 
-	breaker.collector.ReportNew(BreakerConfig{
-		MaxConcurrency: breaker.maxConcurrency,
-	})
+	if tc, ok := breaker.tripCondition.(clockSetter); ok {
+		tc.setClock(breaker.clock)
+	}
+
+	if breaker.throttleWindow > 0 {
+		breaker.throttle = newAdaptiveThrottle(breaker.throttleK, breaker.throttleWindow, breaker.clock)
+	}
+
+	if breaker.rateLimit > 0 {
+		breaker.rateLimiter = newTokenBucket(breaker.clock, breaker.rateLimit, breaker.rateLimitBurst)
+	}
+
+	if breaker.poolCoreSize > 0 {
+		breaker.pool = newWorkerPool(
+			breaker.poolCoreSize,
+			breaker.poolQueueSize,
+			breaker.poolRejectionThreshold,
+			breaker.collector,
+		)
+	}
+
+	config := BreakerConfig{
+		MaxConcurrency:              breaker.maxConcurrency,
+		FallbackMaxConcurrency:      breaker.fallbackMaxConcurrency,
+		PoolCoreSize:                breaker.poolCoreSize,
+		PoolQueueSize:               breaker.poolQueueSize,
+		QueueSizeRejectionThreshold: breaker.poolRejectionThreshold,
+	}
+
+	if tc, ok := breaker.tripCondition.(configReporter); ok {
+		tc.reportConfig(&config)
+	}
 
-	breaker.setState(StateClosed)
+	breaker.collector.ReportNew(config)
+
+	if breaker.adaptiveTimeout != nil {
+		breaker.collector.ReportDuration(EventTypeInvocationTimeout, breaker.adaptiveTimeout.current())
+	}
+
+	if breaker.throttle != nil {
+		reportGauge(breaker.collector, EventTypeRejectProbability, 0)
+	}
+
+	breaker.state = StateClosed
+	breaker.collector.ReportState(StateClosed)
 	return breaker
 }
 
+func WithName(name string) BreakerConfigFunc {
+	return func(cb *circuitBreaker) { cb.name = name }
+}
+
 func WithInvocationTimeout(timeout time.Duration) BreakerConfigFunc {
 	return func(cb *circuitBreaker) { cb.invocationTimeout = timeout }
 }
 
+// WithAdaptiveInvocationTimeout derives the invocation timeout from the durations of
+// the last window successful calls. The timeout is the given percentile (a value in
+// [0, 1]) of those durations times the multiplier, clamped between min and max. The
+// max is used until window calls have succeeded. Each time the timeout changes, it
+// is reported to the collector as an EventTypeInvocationTimeout duration.
+func WithAdaptiveInvocationTimeout(window int, percentile, multiplier float64, min, max time.Duration) BreakerConfigFunc {
+	return func(cb *circuitBreaker) {
+		cb.adaptiveTimeout = newAdaptiveTimeout(window, percentile, multiplier, min, max)
+	}
+}
+
 func WithHalfClosedRetryProbability(probability float64) BreakerConfigFunc {
 	return func(cb *circuitBreaker) { cb.halfClosedRetryProbability = probability }
 }
 
+// WithHalfClosedProbes replaces the probabilistic half-closed retry behavior with a
+// deterministic one. While half-closed, at most maxProbes calls are attempted at once.
+// The breaker closes after successThreshold consecutive successful probes and re-opens
+// on the first failed probe. An open breaker does not close without successful probes,
+// even if its trip condition clears.
+func WithHalfClosedProbes(maxProbes, successThreshold int) BreakerConfigFunc {
+	return func(cb *circuitBreaker) {
+		cb.halfClosedMaxProbes = maxProbes
+		cb.halfClosedSuccessThreshold = successThreshold
+	}
+}
+
 func WithResetBackoff(resetBackoff backoff.Backoff) BreakerConfigFunc {
 	return func(cb *circuitBreaker) { cb.resetBackoff = resetBackoff }
 }
 
+// WithRetryAfterBounds limits the open period requested by errors which implement
+// RetryAfterError. A zero max does not limit the open period.
+func WithRetryAfterBounds(min, max time.Duration) BreakerConfigFunc {
+	return func(cb *circuitBreaker) {
+		cb.minRetryAfter = min
+		cb.maxRetryAfter = max
+	}
+}
+
 func WithFailureInterpreter(failureInterpreter FailureInterpreter) BreakerConfigFunc {
 	return func(cb *circuitBreaker) { cb.failureInterpreter = failureInterpreter }
 }
 
+// WithRetry causes a failed call to be attempted again, up to a total of maxAttempts
+// attempts, waiting between attempts according to the given backoff (not waiting if
+// nil). Only errors on which the retryable interpreter trips are retried (all errors
+// if nil). Calls which are rejected by the breaker (e.g. because the circuit is open)
+// are not retried, and no attempt is made if the caller's context would expire before
+// it begins.
+func WithRetry(retryBackoff backoff.Backoff, maxAttempts int, retryable FailureInterpreter) BreakerConfigFunc {
+	if retryBackoff == nil {
+		retryBackoff = backoff.NewZeroBackoff()
+	}
+
+	if retryable == nil {
+		retryable = NewAnyErrorFailureInterpreter()
+	}
+
+	return func(cb *circuitBreaker) {
+		cb.retryBackoff = retryBackoff
+		cb.retryMaxAttempts = maxAttempts
+		cb.retryInterpreter = retryable
+	}
+}
+
+// WithRetryBudget limits the retries made due to the breaker's retry policy. Once the
+// budget is exhausted, failed calls are not retried and a RetryBudgetError is returned.
+// The same budget may be given to several breakers.
+func WithRetryBudget(budget *RetryBudget) BreakerConfigFunc {
+	return func(cb *circuitBreaker) { cb.retryBudget = budget }
+}
+
+// WithHedging causes a registry to start a second attempt of a call which has not
+// completed after the given delay. Only calls whose context was created by AllowHedging
+// are hedged, so calls which are not idempotent can share the breaker. The first
+// attempt to succeed wins and the other attempt is canceled. Both attempts acquire a
+// token from the breaker's semaphore. Hedged attempts are limited by the given budget
+// (unlimited if nil), which is funded by first attempts which succeed before the delay
+// elapses.
+func WithHedging(delay time.Duration, budget *RetryBudget) BreakerConfigFunc {
+	return func(cb *circuitBreaker) { cb.hedging = &hedgePolicy{delay: delay, budget: budget} }
+}
+
+// WithAdaptiveHedging behaves like WithHedging, but the delay is the given percentile
+// (a value in [0, 1]) of the durations of the last window successful calls, clamped
+// between min and max. The max is used until window calls have succeeded.
+func WithAdaptiveHedging(window int, percentile float64, min, max time.Duration, budget *RetryBudget) BreakerConfigFunc {
+	return func(cb *circuitBreaker) {
+		cb.hedging = &hedgePolicy{
+			adaptive: newAdaptiveTimeout(window, percentile, 1, min, max),
+			budget:   budget,
+		}
+	}
+}
+
 func WithTripCondition(tripCondition TripCondition) BreakerConfigFunc {
 	return func(cb *circuitBreaker) { cb.tripCondition = tripCondition }
 }
@@ -141,10 +348,109 @@ func WithMaxConcurrencyTimeout(timeout time.Duration) BreakerConfigFunc {
 	return func(cb *circuitBreaker) { cb.maxConcurrencyTimeout = timeout }
 }
 
+// WithConcurrencyLimiter bounds the number of concurrent invocations of a breaker in
+// a registry by the current limit of the given limiter instead of a fixed maximum. The
+// limiter observes each invocation made through the registry. The current limit is
+// reported to the collector as an EventTypeConcurrencyLimit gauge. A limiter should not
+// be shared between breakers, and is not used by breakers with a thread pool.
+func WithConcurrencyLimiter(limiter ConcurrencyLimiter) BreakerConfigFunc {
+	return func(cb *circuitBreaker) { cb.concurrencyLimiter = limiter }
+}
+
+// WithRateLimit limits the calls made through a registry to rate calls per second, with
+// bursts of up to burst calls. Each attempt (including retries and hedges) requires a
+// token. Calls over the limit fail immediately with a RateLimitError unless a timeout is
+// set via WithRateLimitTimeout. A rate of zero disables the limit.
+func WithRateLimit(rate float64, burst int) BreakerConfigFunc {
+	return func(cb *circuitBreaker) {
+		cb.rateLimit = rate
+		cb.rateLimitBurst = burst
+	}
+}
+
+// WithRateLimitTimeout sets how long you are willing to wait for the rate limit of the
+// breaker before the call fails. Calls which could not proceed within the timeout fail
+// immediately rather than waiting for the timeout to elapse.
+func WithRateLimitTimeout(timeout time.Duration) BreakerConfigFunc {
+	return func(cb *circuitBreaker) { cb.rateLimitTimeout = timeout }
+}
+
+// WithAdaptiveThrottling enables client-side adaptive throttling. While the breaker is
+// closed, calls are rejected locally (as if the circuit were open) with probability
+// max(0, (requests - k*accepts) / (requests + 1)), where requests is the number of calls
+// attempted within the window and accepts is the number of those which did not fail.
+// Lower values of k reject more aggressively. The reject probability is reported to
+// the collector as an EventTypeRejectProbability gauge.
+func WithAdaptiveThrottling(k float64, window time.Duration) BreakerConfigFunc {
+	return func(cb *circuitBreaker) {
+		cb.throttleK = k
+		cb.throttleWindow = window
+	}
+}
+
+// WithFallbackTimeout sets how long the fallback function of a call made through a
+// registry may run before the call fails with ErrFallbackTimeout. The context passed
+// to the fallback function has a deadline set to the timeout. Zero allows for unbounded
+// runtime, which is the default.
+func WithFallbackTimeout(timeout time.Duration) BreakerConfigFunc {
+	return func(cb *circuitBreaker) { cb.fallbackTimeout = timeout }
+}
+
+// WithFallbackMaxConcurrency sets the maximum number of fallback functions of calls
+// made through a registry which may run concurrently. Calls which fail while the limit
+// is reached do not invoke the fallback and fail with ErrFallbackRejected. A value of
+// zero disables the limit, which is the default.
+func WithFallbackMaxConcurrency(maxConcurrency int) BreakerConfigFunc {
+	return func(cb *circuitBreaker) { cb.fallbackMaxConcurrency = maxConcurrency }
+}
+
+// WithThreadPool isolates calls by running breaker functions on a fixed pool of
+// coreSize worker goroutines instead of a new goroutine per call. Calls which arrive
+// while all workers are busy wait in a queue of up to queueSize calls. Calls which
+// arrive once the queue holds rejectionThreshold calls (if positive) or is full are
+// rejected with a MaxConcurrencyError. A queued call is not run if its caller stops
+// waiting before a worker is free. This replaces the registry's semaphore. The
+// workers run until the breaker (or its registry) is closed.
+func WithThreadPool(coreSize, queueSize, rejectionThreshold int) BreakerConfigFunc {
+	return func(cb *circuitBreaker) {
+		cb.poolCoreSize = coreSize
+		cb.poolQueueSize = queueSize
+		cb.poolRejectionThreshold = rejectionThreshold
+	}
+}
+
 func WithCollector(collector MetricCollector) BreakerConfigFunc {
 	return func(cb *circuitBreaker) { cb.collector = collector }
 }
 
+// WithPanicRecovery controls whether or not a panic in the protected function
+// is recovered and converted into a PanicError. Panic recovery is enabled by
+// default.
+func WithPanicRecovery(enabled bool) BreakerConfigFunc {
+	return func(cb *circuitBreaker) { cb.panicRecovery = enabled }
+}
+
+// WithMaxAbandoned sets the maximum number of invocations which may still be running
+// after the breaker has stopped waiting for them (due to a timeout or cancellation).
+// Once this number is reached, calls fail immediately with ErrMaxAbandoned until some
+// of the abandoned invocations return. A value of zero disables the limit.
+func WithMaxAbandoned(maxAbandoned int) BreakerConfigFunc {
+	return func(cb *circuitBreaker) { cb.maxAbandoned = maxAbandoned }
+}
+
+// WithDryRun causes the breaker to invoke the protected function even when the
+// circuit is open. The breaker's state machine still runs and reports metrics and
+// state changes, but calls which would have been short-circuited are reported with
+// an EventTypeWouldShortCircuit event instead. This allows a trip condition to be
+// tuned against real traffic before it is enforced.
+func WithDryRun() BreakerConfigFunc {
+	return func(cb *circuitBreaker) { cb.dryRun = true }
+}
+
+func WithStateChangeListener(listener StateChangeListener) BreakerConfigFunc {
+	return func(cb *circuitBreaker) { cb.listeners = append(cb.listeners, listener) }
+}
+
 func withClock(clock glock.Clock) BreakerConfigFunc {
 	return func(cb *circuitBreaker) { cb.clock = clock }
 }
@@ -154,32 +460,41 @@ func withClock(clock glock.Clock) BreakerConfigFunc {
 
 func (cb *circuitBreaker) Trip() {
 	cb.mutex.Lock()
-	defer cb.mutex.Unlock()
+	defer cb.unlock()
 
-	cb.setState(StateHardOpen)
+	cb.setState(StateHardOpen, StateChangeReasonManualTrip)
 }
 
 func (cb *circuitBreaker) Reset() {
-	cb.mutex.Lock()
-	defer cb.mutex.Unlock()
-
-	cb.setState(StateClosed)
-	cb.resetTimeout = nil
-	cb.resetBackoff.Reset()
-	cb.tripCondition.Success()
+	cb.reset(StateChangeReasonManualReset)
 }
 
 func (cb *circuitBreaker) ShouldTry() bool {
+	ok, _ := cb.shouldTry()
+	return ok
+}
+
+// shouldTry behaves like ShouldTry, but also returns a non-zero probe token if
+// the call is admitted as a probe. The token must be given back along with the
+// result of the call so that only the results of probes admitted during the
+// current half-closed period count as probe results.
+func (cb *circuitBreaker) shouldTry() (bool, uint64) {
 	cb.mutex.Lock()
-	defer cb.mutex.Unlock()
+	defer cb.unlock()
 
 	if cb.state == StateHardOpen {
-		return false
+		return false, 0
 	}
 
-	if !cb.tripCondition.ShouldTrip() {
-		cb.setState(StateClosed)
-		return true
+	if cb.state == StateHalfClosed && cb.halfClosedMaxProbes > 0 {
+		return cb.tryProbe()
+	}
+
+	if !cb.tripCondition.ShouldTrip() && (cb.state == StateClosed || cb.halfClosedMaxProbes == 0) {
+		// When probing is enabled, an open breaker closes only
+		// after enough probes have succeeded.
+		cb.setState(StateClosed, StateChangeReasonTripConditionCleared)
+		return cb.admitThrottled(), 0
 	}
 
 	if cb.state == StateClosed {
@@ -187,48 +502,170 @@ func (cb *circuitBreaker) ShouldTry() bool {
 	}
 
 	if cb.state != StateOpen {
-		reset := cb.resetBackoff.NextInterval()
+		reset := cb.nextResetTimeout()
 		cb.resetTimeout = &reset
 	}
 
 	if cb.resetTimeoutElapsed() {
-		cb.setState(StateHalfClosed)
-		return rand.Float64() < cb.halfClosedRetryProbability
+		cb.setState(StateHalfClosed, StateChangeReasonResetTimeoutElapsed)
+
+		if cb.halfClosedMaxProbes > 0 {
+			cb.activeProbes = 0
+			cb.probeSuccesses = 0
+			cb.probeGeneration++
+			return cb.tryProbe()
+		}
+
+		return rand.Float64() < cb.halfClosedRetryProbability, 0
+	}
+
+	reason := StateChangeReasonTripped
+	if cb.state == StateHalfClosed {
+		reason = StateChangeReasonProbeFailed
+	}
+
+	cb.setState(StateOpen, reason)
+	return false, 0
+}
+
+func (cb *circuitBreaker) State() CircuitState {
+	cb.mutex.RLock()
+	defer cb.mutex.RUnlock()
+
+	return cb.state
+}
+
+func (cb *circuitBreaker) Snapshot() Snapshot {
+	cb.mutex.RLock()
+	defer cb.mutex.RUnlock()
+
+	snapshot := Snapshot{
+		Name:          cb.name,
+		State:         cb.state,
+		TripCondition: describeTripCondition(cb.tripCondition),
+		Abandoned:     int(atomic.LoadInt64(&cb.abandoned)),
+	}
+
+	if cb.lastFailureTime != nil {
+		snapshot.LastFailureTime = *cb.lastFailureTime
+	}
+
+	if cb.resetTimeout != nil {
+		snapshot.ResetTimeout = *cb.resetTimeout
 	}
 
-	cb.setState(StateOpen)
-	return false
+	if cb.state == StateOpen && cb.lastFailureTime != nil && cb.resetTimeout != nil {
+		snapshot.NextAttemptTime = cb.lastFailureTime.Add(*cb.resetTimeout)
+	}
+
+	return snapshot
 }
 
 func (cb *circuitBreaker) MarkResult(err error) bool {
-	if err != nil && (err == ErrInvocationTimeout || cb.failureInterpreter.ShouldTrip(err)) {
-		cb.mutex.Lock()
-		defer cb.mutex.Unlock()
+	// Results given to this method are not tied to the call to ShouldTry
+	// which admitted them, so they are attributed to the current probes.
+	probe := cb.currentProbe()
 
-		now := cb.clock.Now()
-		cb.lastFailureTime = &now
-		cb.tripCondition.Failure()
-		return false
+	if errors.Is(err, context.Canceled) {
+		cb.markCancelled(probe)
+		return true
 	}
 
-	cb.Reset()
-	return true
+	return cb.markResult(err, nil, probe)
 }
 
 func (cb *circuitBreaker) Call(f BreakerFunc) error {
-	if !cb.ShouldTry() {
-		cb.collector.ReportCount(EventTypeShortCircuit)
-		return ErrCircuitOpen
+	return cb.CallContext(context.Background(), f)
+}
+
+func (cb *circuitBreaker) CallContext(ctx context.Context, f BreakerFunc) error {
+	return cb.withRetries(ctx, func() error {
+		return cb.callContext(ctx, f)
+	})
+}
+
+func (cb *circuitBreaker) CallAsync(f BreakerFunc) <-chan error {
+	return toErrChan(func() error {
+		return cb.Call(f)
+	})
+}
+
+func (cb *circuitBreaker) Close() error {
+	if cb.pool != nil {
+		cb.pool.close()
 	}
 
-	start := time.Now()
-	err := callWithTimeout(f, cb.clock, cb.invocationTimeout)
-	elapsed := time.Now().Sub(start)
+	return nil
+}
+
+//
+// Internal Methods
+
+// callContext makes a single attempt to invoke the given function.
+func (cb *circuitBreaker) callContext(ctx context.Context, f BreakerFunc) error {
+	if cb.tooManyAbandoned() {
+		cb.collector.ReportCount(EventTypeRejection)
+		return ErrMaxAbandoned
+	}
+
+	var slot *poolSlot
+	if cb.pool != nil {
+		if slot = cb.pool.reserve(); slot == nil {
+			cb.collector.ReportCount(EventTypeRejection)
+			return &MaxConcurrencyError{Name: cb.name, MaxConcurrency: cb.pool.coreSize}
+		}
+
+		defer slot.release()
+	}
+
+	shadow := false
+	ok, probe := cb.shouldTry()
+	if !ok {
+		if !cb.dryRun {
+			cb.collector.ReportCount(EventTypeShortCircuit)
+			return cb.circuitOpenError()
+		}
+
+		cb.collector.ReportCount(EventTypeWouldShortCircuit)
+		shadow = true
+	}
+
+	start := cb.clock.Now()
+	err := cb.invoke(ctx, f, slot)
+	elapsed := cb.clock.Now().Sub(start)
 
 	cb.collector.ReportDuration(EventTypeRunDuration, elapsed)
 
-	if !cb.MarkResult(err) {
-		if err == ErrInvocationTimeout {
+	if err == nil {
+		cb.observeLatency(elapsed)
+	}
+
+	if isCallerError(ctx, err) {
+		if !shadow {
+			cb.markCancelled(probe)
+		}
+
+		cb.collector.ReportCount(EventTypeCancelled)
+		return err
+	}
+
+	var panicErr *PanicError
+	if errors.As(err, &panicErr) {
+		cb.collector.ReportCount(EventTypePanic)
+	}
+
+	var success bool
+	if shadow {
+		// This call would not have been made if the breaker were enforcing
+		// its state, so the result is reported but must not influence the
+		// state machine.
+		success = !cb.isFailure(err)
+	} else {
+		success = cb.markResult(err, &elapsed, probe)
+	}
+
+	if !success {
+		if errors.Is(err, ErrInvocationTimeout) {
 			cb.collector.ReportCount(EventTypeTimeout)
 		} else {
 			cb.collector.ReportCount(EventTypeError)
@@ -240,17 +677,231 @@ func (cb *circuitBreaker) Call(f BreakerFunc) error {
 	return err
 }
 
-func (cb *circuitBreaker) CallAsync(f BreakerFunc) <-chan error {
-	return toErrChan(func() error {
-		return cb.Call(f)
-	})
+func (cb *circuitBreaker) reset(reason StateChangeReason) {
+	cb.mutex.Lock()
+	defer cb.unlock()
+
+	cb.close(reason)
+	cb.tripCondition.Success()
 }
 
-//
-// Internal Methods
+// markResult updates the state of the breaker with the result of a call. The
+// duration of the call is given to the trip condition when it is known. The
+// probe token is the one returned by shouldTry when the call was admitted.
+func (cb *circuitBreaker) markResult(err error, duration *time.Duration, probe uint64) bool {
+	if cb.isFailure(err) {
+		cb.markFailure(duration, retryAfter(err), probe)
+		return false
+	}
+
+	if cb.throttle != nil {
+		cb.throttle.accept()
+	}
+
+	cb.markSuccess(duration, probe)
+	return true
+}
+
+// isFailure returns true if the given error should count against the trip
+// condition.
+func (cb *circuitBreaker) isFailure(err error) bool {
+	return err != nil && (errors.Is(err, ErrInvocationTimeout) || cb.failureInterpreter.ShouldTrip(err))
+}
+
+func (cb *circuitBreaker) markSuccess(duration *time.Duration, probe uint64) {
+	cb.mutex.Lock()
+	defer cb.unlock()
+
+	if tc, ok := cb.tripCondition.(DurationAwareTripCondition); ok && duration != nil {
+		tc.SuccessWithDuration(*duration)
+	} else {
+		cb.tripCondition.Success()
+	}
+
+	if cb.halfClosedMaxProbes > 0 {
+		switch {
+		case cb.isProbe(probe):
+			cb.releaseProbe()
+			cb.probeSuccesses++
+
+			if cb.probeSuccesses < cb.halfClosedSuccessThreshold {
+				return
+			}
+
+		case cb.state != StateClosed:
+			// Results of calls admitted before the breaker opened
+			// must not close the breaker without a successful probe.
+			return
+		}
+	}
+
+	cb.close(StateChangeReasonProbeSucceeded)
+}
+
+func (cb *circuitBreaker) markFailure(duration, retryAfter *time.Duration, probe uint64) {
+	cb.mutex.Lock()
+	defer cb.unlock()
+
+	now := cb.clock.Now()
+	cb.lastFailureTime = &now
+	cb.retryAfter = retryAfter
+
+	if tc, ok := cb.tripCondition.(DurationAwareTripCondition); ok && duration != nil {
+		tc.FailureWithDuration(*duration)
+	} else {
+		cb.tripCondition.Failure()
+	}
+
+	if cb.isProbe(probe) {
+		// Re-open immediately instead of waiting for the next call
+		// to ShouldTry so that concurrent probes are not admitted.
+		reset := cb.nextResetTimeout()
+		cb.resetTimeout = &reset
+		cb.releaseProbe()
+		cb.setState(StateOpen, StateChangeReasonProbeFailed)
+		return
+	}
+
+	if !cb.tripCondition.ShouldTrip() {
+		return
+	}
+
+	// Open as soon as the trip condition is satisfied so that the state
+	// of the breaker and its listeners do not wait for the next call.
+	switch {
+	case cb.state == StateClosed:
+		cb.resetBackoff.Reset()
+		reset := cb.nextResetTimeout()
+		cb.resetTimeout = &reset
+		cb.setState(StateOpen, StateChangeReasonTripped)
+
+	case cb.state == StateHalfClosed && cb.halfClosedMaxProbes == 0:
+		reset := cb.nextResetTimeout()
+		cb.resetTimeout = &reset
+		cb.setState(StateOpen, StateChangeReasonProbeFailed)
+	}
+}
+
+func (cb *circuitBreaker) markCancelled(probe uint64) {
+	cb.mutex.Lock()
+	defer cb.unlock()
+
+	if cb.isProbe(probe) {
+		cb.releaseProbe()
+	}
+}
+
+// probing returns true if the breaker is half-closed and is configured to
+// admit a bounded number of probes. The lock must be held by the caller.
+func (cb *circuitBreaker) probing() bool {
+	return cb.state == StateHalfClosed && cb.halfClosedMaxProbes > 0
+}
+
+// isProbe returns true if the given token belongs to a probe admitted during
+// the current half-closed period. The lock must be held by the caller.
+func (cb *circuitBreaker) isProbe(probe uint64) bool {
+	return probe != 0 && probe == cb.probeGeneration && cb.probing()
+}
+
+// currentProbe returns the token of the probes admitted during the current
+// half-closed period, or zero if the breaker is not probing.
+func (cb *circuitBreaker) currentProbe() uint64 {
+	cb.mutex.RLock()
+	defer cb.mutex.RUnlock()
+
+	if !cb.probing() {
+		return 0
+	}
 
-func (cb *circuitBreaker) setState(state CircuitState) {
+	return cb.probeGeneration
+}
+
+// tryProbe admits a probe if fewer than the maximum number of probes are in
+// flight, and returns its token. The lock must be held by the caller.
+func (cb *circuitBreaker) tryProbe() (bool, uint64) {
+	if cb.activeProbes >= cb.halfClosedMaxProbes {
+		return false, 0
+	}
+
+	cb.activeProbes++
+	return true, cb.probeGeneration
+}
+
+func (cb *circuitBreaker) releaseProbe() {
+	if cb.activeProbes > 0 {
+		cb.activeProbes--
+	}
+}
+
+// admitThrottled records a request with the adaptive throttle, if any, and
+// returns false if the request should be rejected locally.
+func (cb *circuitBreaker) admitThrottled() bool {
+	if cb.throttle == nil {
+		return true
+	}
+
+	probability, changed := cb.throttle.request()
+	if changed {
+		reportGauge(cb.collector, EventTypeRejectProbability, probability)
+	}
+
+	return rand.Float64() >= probability
+}
+
+func (cb *circuitBreaker) close(reason StateChangeReason) {
+	cb.setState(StateClosed, reason)
+	cb.resetTimeout = nil
+	cb.retryAfter = nil
+	cb.resetBackoff.Reset()
+}
+
+// nextResetTimeout determines how long the breaker stays open. The open period
+// requested by the most recent failure, if any, overrides the reset backoff.
+func (cb *circuitBreaker) nextResetTimeout() time.Duration {
+	if cb.retryAfter == nil {
+		return cb.resetBackoff.NextInterval()
+	}
+
+	reset := *cb.retryAfter
+	cb.retryAfter = nil
+
+	if reset < cb.minRetryAfter {
+		reset = cb.minRetryAfter
+	}
+
+	if cb.maxRetryAfter > 0 && reset > cb.maxRetryAfter {
+		reset = cb.maxRetryAfter
+	}
+
+	return reset
+}
+
+// unlock releases the breaker's lock, then invokes the registered state change
+// listeners for each state change which occurred while the lock was held.
+func (cb *circuitBreaker) unlock() {
+	changes := cb.changes
+	cb.changes = nil
+	cb.mutex.Unlock()
+
+	for _, change := range changes {
+		for _, listener := range cb.listeners {
+			listener(change)
+		}
+	}
+}
+
+func (cb *circuitBreaker) setState(state CircuitState, reason StateChangeReason) {
 	if cb.state != state {
+		if len(cb.listeners) > 0 {
+			cb.changes = append(cb.changes, StateChange{
+				Name:   cb.name,
+				From:   cb.state,
+				To:     state,
+				Time:   cb.clock.Now(),
+				Reason: reason,
+			})
+		}
+
 		cb.state = state
 		cb.collector.ReportState(state)
 	}
@@ -268,23 +919,101 @@ func (cb *circuitBreaker) resetTimeoutElapsed() bool {
 	return cb.clock.Now().Sub(*cb.lastFailureTime) >= *cb.resetTimeout
 }
 
-func callWithTimeout(f BreakerFunc, clock glock.Clock, timeout time.Duration) error {
-	if timeout == 0 {
-		return f(context.Background())
+// currentInvocationTimeout returns the invocation timeout for the next call.
+func (cb *circuitBreaker) currentInvocationTimeout() time.Duration {
+	if cb.adaptiveTimeout != nil {
+		return cb.adaptiveTimeout.current()
+	}
+
+	return cb.invocationTimeout
+}
+
+// observeLatency updates the adaptive invocation timeout and hedge delay, if
+// enabled, with the duration of a successful call.
+func (cb *circuitBreaker) observeLatency(duration time.Duration) {
+	if cb.adaptiveTimeout != nil {
+		if timeout, changed := cb.adaptiveTimeout.observe(duration); changed {
+			cb.collector.ReportDuration(EventTypeInvocationTimeout, timeout)
+		}
+	}
+
+	if cb.hedging != nil && cb.hedging.adaptive != nil {
+		cb.hedging.adaptive.observe(duration)
+	}
+}
+
+// circuitOpenError creates the error returned when the breaker rejects a call.
+func (cb *circuitBreaker) circuitOpenError() error {
+	cb.mutex.RLock()
+	defer cb.mutex.RUnlock()
+
+	err := &CircuitOpenError{
+		Name:  cb.name,
+		State: cb.state,
+	}
+
+	if cb.state == StateOpen && cb.lastFailureTime != nil && cb.resetTimeout != nil {
+		if retryAfter := cb.lastFailureTime.Add(*cb.resetTimeout).Sub(cb.clock.Now()); retryAfter > 0 {
+			err.RetryAfter = retryAfter
+		}
+	}
+
+	return err
+}
+
+// isCallerError determines if the given error was caused by the caller of the
+// breaker (the context was canceled) rather than by the protected function. An
+// elapsed deadline is not caused by the caller: the dependency was too slow.
+func isCallerError(ctx context.Context, err error) bool {
+	return err != nil && errors.Is(ctx.Err(), context.Canceled)
+}
+
+func callWithTimeout(ctx context.Context, f BreakerFunc, clock glock.Clock, timeout time.Duration) error {
+	return spawnWithTimeout(ctx, f, clock, timeout, nil)
+}
+
+// spawnWithTimeout invokes the given function via the given spawn function and waits
+// for it to complete, for the timeout to elapse, or for the context to be canceled. The
+// spawn function is given the context of the call so that it can skip a call which has
+// already been abandoned. If spawn is nil, the function is invoked in a new goroutine,
+// or directly if there is no timeout and the context can never be canceled.
+func spawnWithTimeout(ctx context.Context, f BreakerFunc, clock glock.Clock, timeout time.Duration, spawn func(context.Context, func() error) <-chan error) error {
+	if spawn == nil {
+		if timeout == 0 && ctx.Done() == nil {
+			return f(ctx)
+		}
+
+		spawn = func(_ context.Context, f func() error) <-chan error { return toErrChan(f) }
+	}
+
+	var (
+		callCtx = ctx
+		cancel  = context.CancelFunc(func() {})
+	)
+
+	if timeout != 0 {
+		callCtx, cancel = withClockTimeout(ctx, clock, timeout)
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	ch := toErrChan(func() error {
-		return f(ctx)
+	ch := spawn(callCtx, func() error {
+		return f(callCtx)
 	})
 
 	select {
 	case err := <-ch:
-		return err
+		// The function may observe the deadline and return before we do.
+		// Make sure the caller sees a timeout in this case as well.
+		if err == nil || errors.Is(ctx.Err(), context.Canceled) || callCtx.Err() != context.DeadlineExceeded {
+			return err
+		}
 
-	case <-clock.After(timeout):
-		return ErrInvocationTimeout
+	case <-callCtx.Done():
+		if err := ctx.Err(); errors.Is(err, context.Canceled) {
+			return err
+		}
 	}
+
+	return ErrInvocationTimeout
 }
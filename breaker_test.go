@@ -4,12 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/aphistic/sweet"
 	"github.com/efritz/backoff"
 	"github.com/efritz/glock"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
 )
 
 type BreakerSuite struct{}
@@ -29,7 +31,7 @@ func (s *BreakerSuite) TestNaturalErrorTrip(t sweet.T) {
 		Expect(breaker.Call(errFunc)).To(Equal(testErr))
 	}
 
-	Expect(breaker.Call(errFunc)).To(Equal(ErrCircuitOpen))
+	Expect(breaker.Call(errFunc)).To(beError(ErrCircuitOpen))
 }
 
 func (s *BreakerSuite) TestTimeout(t sweet.T) {
@@ -42,7 +44,7 @@ func (s *BreakerSuite) TestTimeout(t sweet.T) {
 		clock.BlockingAdvance(time.Minute)
 	}()
 
-	Expect(breaker.Call(blockingFunc)).To(Equal(ErrInvocationTimeout))
+	Expect(breaker.Call(blockingFunc)).To(beError(ErrInvocationTimeout))
 }
 
 func (s *BreakerSuite) TestTimeoutTrip(t sweet.T) {
@@ -58,10 +60,217 @@ func (s *BreakerSuite) TestTimeoutTrip(t sweet.T) {
 	}()
 
 	for i := 0; i < 5; i++ {
-		Expect(breaker.Call(blockingFunc)).To(Equal(ErrInvocationTimeout))
+		Expect(breaker.Call(blockingFunc)).To(beError(ErrInvocationTimeout))
 	}
 
-	Expect(breaker.Call(blockingFunc)).To(Equal(ErrCircuitOpen))
+	Expect(breaker.Call(blockingFunc)).To(beError(ErrCircuitOpen))
+}
+
+func (s *BreakerSuite) TestCallContext(t sweet.T) {
+	var (
+		breaker = NewCircuitBreaker(testConfig())
+		ctx     = context.WithValue(context.Background(), "key", "value")
+		value   interface{}
+	)
+
+	err := breaker.CallContext(ctx, func(ctx context.Context) error {
+		value = ctx.Value("key")
+		return nil
+	})
+
+	Expect(err).To(BeNil())
+	Expect(value).To(Equal("value"))
+}
+
+func (s *BreakerSuite) TestCallContextCancel(t sweet.T) {
+	var (
+		clock       = glock.NewMockClock()
+		breaker     = NewCircuitBreaker(testConfig(), withClock(clock))
+		errors      = make(chan error)
+		ctx, cancel = context.WithCancel(context.Background())
+	)
+
+	go func() {
+		defer close(errors)
+		errors <- breaker.CallContext(ctx, blockingFunc)
+	}()
+
+	Consistently(errors).ShouldNot(Receive())
+	cancel()
+	Eventually(errors).Should(Receive(Equal(context.Canceled)))
+}
+
+func (s *BreakerSuite) TestCallContextCancelNoTimeout(t sweet.T) {
+	var (
+		breaker     = NewCircuitBreaker(testConfig(), WithInvocationTimeout(0))
+		errors      = make(chan error)
+		block       = make(chan struct{})
+		ctx, cancel = context.WithCancel(context.Background())
+	)
+
+	defer close(block)
+
+	go func() {
+		defer close(errors)
+		errors <- breaker.CallContext(ctx, func(ctx context.Context) error {
+			<-block
+			return nil
+		})
+	}()
+
+	Consistently(errors).ShouldNot(Receive())
+	cancel()
+
+	// Returns without waiting for the function
+	Eventually(errors).Should(Receive(Equal(context.Canceled)))
+	Expect(breaker.Snapshot().Abandoned).To(Equal(1))
+}
+
+func (s *BreakerSuite) TestCallContextCancelNoTrip(t sweet.T) {
+	var (
+		breaker     = NewCircuitBreaker(testConfig())
+		ctx, cancel = context.WithCancel(context.Background())
+	)
+
+	cancel()
+
+	for i := 0; i < 10; i++ {
+		err := breaker.CallContext(ctx, func(ctx context.Context) error {
+			<-ctx.Done()
+			return testErr
+		})
+
+		Expect(err).NotTo(BeNil())
+		Expect(err).NotTo(beError(ErrCircuitOpen))
+	}
+
+	Expect(breaker.Call(errFunc)).To(Equal(testErr))
+}
+
+func (s *BreakerSuite) TestCallContextDeadlineTrips(t sweet.T) {
+	var (
+		collector = newTestCollector()
+		breaker   = NewCircuitBreaker(
+			testConfig(),
+			WithCollector(collector),
+			WithTripCondition(NewConsecutiveFailureTripCondition(3)),
+		)
+	)
+
+	// The caller's deadline is sooner than the invocation timeout
+	for i := 0; i < 3; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+		Expect(breaker.CallContext(ctx, blockingFunc)).To(beError(ErrInvocationTimeout))
+		cancel()
+	}
+
+	Expect(collector.count(EventTypeTimeout)).To(Equal(3))
+	Expect(collector.count(EventTypeCancelled)).To(Equal(0))
+	Expect(breaker.Call(nilFunc)).To(beError(ErrCircuitOpen))
+}
+
+func (s *BreakerSuite) TestDependencyCanceledTrips(t sweet.T) {
+	breaker := NewCircuitBreaker(
+		testConfig(),
+		WithTripCondition(NewConsecutiveFailureTripCondition(3)),
+	)
+
+	// The caller's context is still live
+	for i := 0; i < 3; i++ {
+		Expect(breaker.Call(func(ctx context.Context) error {
+			return fmt.Errorf("upstream: %w", context.Canceled)
+		})).To(beError(context.Canceled))
+	}
+
+	Expect(breaker.Call(nilFunc)).To(beError(ErrCircuitOpen))
+}
+
+func (s *BreakerSuite) TestTimeoutDeadline(t sweet.T) {
+	var (
+		clock   = glock.NewMockClock()
+		breaker = NewCircuitBreaker(testConfig(), withClock(clock))
+	)
+
+	err := breaker.Call(func(ctx context.Context) error {
+		deadline, ok := ctx.Deadline()
+		Expect(ok).To(BeTrue())
+		Expect(deadline).To(Equal(clock.Now().Add(time.Minute)))
+		return nil
+	})
+
+	Expect(err).To(BeNil())
+}
+
+func (s *BreakerSuite) TestTimeoutIsDeadlineExceeded(t sweet.T) {
+	Expect(errors.Is(ErrInvocationTimeout, context.DeadlineExceeded)).To(BeTrue())
+}
+
+func (s *BreakerSuite) TestPanic(t sweet.T) {
+	breaker := NewCircuitBreaker(testConfig())
+
+	for i := 0; i < 5; i++ {
+		err := breaker.Call(panicFunc)
+		Expect(err).To(BeAssignableToTypeOf(&PanicError{}))
+		Expect(err.(*PanicError).Value).To(Equal("utoh"))
+		Expect(string(err.(*PanicError).Stack)).To(ContainSubstring("panicFunc"))
+	}
+
+	Expect(breaker.Call(panicFunc)).To(beError(ErrCircuitOpen))
+}
+
+func (s *BreakerSuite) TestPanicNoTimeout(t sweet.T) {
+	breaker := NewCircuitBreaker(testConfig(), WithInvocationTimeout(0))
+	Expect(breaker.Call(panicFunc)).To(BeAssignableToTypeOf(&PanicError{}))
+}
+
+func (s *BreakerSuite) TestPanicRecoveryDisabled(t sweet.T) {
+	breaker := NewCircuitBreaker(
+		testConfig(),
+		WithInvocationTimeout(0),
+		WithPanicRecovery(false),
+	)
+
+	Expect(func() { breaker.Call(panicFunc) }).To(Panic())
+}
+
+func (s *BreakerSuite) TestAbandoned(t sweet.T) {
+	var (
+		clock     = glock.NewMockClock()
+		collector = newTestCollector()
+		block     = make(chan struct{})
+		breaker   = NewCircuitBreaker(
+			testConfig(),
+			withClock(clock),
+			WithCollector(collector),
+			WithMaxAbandoned(2),
+		)
+	)
+
+	fn := func(ctx context.Context) error {
+		<-block
+		return nil
+	}
+
+	for i := 0; i < 2; i++ {
+		go clock.BlockingAdvance(time.Minute)
+		Expect(breaker.Call(fn)).To(beError(ErrInvocationTimeout))
+	}
+
+	Expect(breaker.Snapshot().Abandoned).To(Equal(2))
+	Expect(collector.count(EventTypeAbandoned)).To(Equal(2))
+	Expect(breaker.Call(nilFunc)).To(Equal(ErrMaxAbandoned))
+	Expect(collector.count(EventTypeRejection)).To(Equal(1))
+
+	clock.Advance(time.Second)
+	block <- struct{}{}
+	Eventually(func() int { return breaker.Snapshot().Abandoned }).Should(Equal(1))
+	Expect(collector.count(EventTypeAbandonedCompleted)).To(Equal(1))
+	Expect(collector.durations(EventTypeAbandonedDuration)).To(HaveLen(1))
+	Expect(collector.durations(EventTypeAbandonedDuration)[0]).To(BeNumerically(">=", time.Second))
+	Expect(breaker.Call(nilFunc)).To(BeNil())
+
+	close(block)
+	Eventually(func() int { return breaker.Snapshot().Abandoned }).Should(Equal(0))
 }
 
 func (s *BreakerSuite) TestTimeoutDisabled(t sweet.T) {
@@ -99,306 +308,1297 @@ func (s *BreakerSuite) TestHalfOpenFailure(t sweet.T) {
 		Expect(breaker.Call(errFunc)).To(Equal(testErr))
 	}
 
-	Expect(breaker.Call(errFunc)).To(Equal(ErrCircuitOpen))
+	Expect(breaker.Call(errFunc)).To(beError(ErrCircuitOpen))
+
+	// Wait for retry backoff
+	clock.Advance(15 * time.Second)
+	Expect(breaker.Call(errFunc)).To(Equal(testErr))
+	Expect(breaker.Call(nilFunc)).To(beError(ErrCircuitOpen))
+}
+
+func (s *BreakerSuite) TestHalfOpenReset(t sweet.T) {
+	var (
+		clock   = glock.NewMockClock()
+		breaker = NewCircuitBreaker(
+			testConfig(),
+			withClock(clock),
+			WithHalfClosedRetryProbability(1),
+		)
+	)
+
+	for i := 0; i < 5; i++ {
+		Expect(breaker.Call(errFunc)).To(Equal(testErr))
+	}
+
+	Expect(breaker.Call(errFunc)).To(beError(ErrCircuitOpen))
+
+	// Wait for retry backoff
+	clock.Advance(15 * time.Second)
+	Expect(breaker.Call(nilFunc)).To(BeNil())
+}
+
+func (s *BreakerSuite) TestHalfOpenProbes(t sweet.T) {
+	var (
+		clock   = glock.NewMockClock()
+		breaker = NewCircuitBreaker(
+			testConfig(),
+			withClock(clock),
+			WithHalfClosedProbes(2, 3),
+		)
+	)
+
+	for i := 0; i < 5; i++ {
+		Expect(breaker.Call(errFunc)).To(Equal(testErr))
+	}
+
+	Expect(breaker.ShouldTry()).To(BeFalse())
+
+	// Wait for retry backoff
+	clock.Advance(15 * time.Second)
+	Expect(breaker.ShouldTry()).To(BeTrue())
+	Expect(breaker.ShouldTry()).To(BeTrue())
+	Expect(breaker.ShouldTry()).To(BeFalse())
+	Expect(breaker.State()).To(Equal(StateHalfClosed))
+
+	breaker.MarkResult(nil)
+	Expect(breaker.State()).To(Equal(StateHalfClosed))
+	Expect(breaker.ShouldTry()).To(BeTrue())
+	Expect(breaker.ShouldTry()).To(BeFalse())
+
+	breaker.MarkResult(nil)
+	Expect(breaker.State()).To(Equal(StateHalfClosed))
+	breaker.MarkResult(nil)
+	Expect(breaker.State()).To(Equal(StateClosed))
+
+	for i := 0; i < 10; i++ {
+		Expect(breaker.ShouldTry()).To(BeTrue())
+	}
+}
+
+func (s *BreakerSuite) TestHalfOpenProbeFailure(t sweet.T) {
+	var (
+		clock   = glock.NewMockClock()
+		breaker = NewCircuitBreaker(
+			testConfig(),
+			withClock(clock),
+			WithHalfClosedProbes(2, 3),
+			WithResetBackoff(backoff.NewLinearBackoff(
+				100*time.Millisecond,
+				50*time.Millisecond,
+				time.Second,
+			)),
+		)
+	)
+
+	for i := 0; i < 5; i++ {
+		Expect(breaker.Call(errFunc)).To(Equal(testErr))
+	}
+
+	Expect(breaker.ShouldTry()).To(BeFalse())
+	clock.Advance(100 * time.Millisecond)
+	Expect(breaker.ShouldTry()).To(BeTrue())
+	Expect(breaker.ShouldTry()).To(BeTrue())
+	breaker.MarkResult(nil)
+
+	// The first failed probe re-opens the breaker
+	breaker.MarkResult(testErr)
+	Expect(breaker.State()).To(Equal(StateOpen))
+	Expect(breaker.Snapshot().ResetTimeout).To(Equal(150 * time.Millisecond))
+
+	// A late probe success does not close the breaker
+	breaker.MarkResult(nil)
+	Expect(breaker.State()).To(Equal(StateOpen))
+
+	clock.Advance(149 * time.Millisecond)
+	Expect(breaker.ShouldTry()).To(BeFalse())
+	clock.Advance(1 * time.Millisecond)
+	Expect(breaker.ShouldTry()).To(BeTrue())
+	Expect(breaker.State()).To(Equal(StateHalfClosed))
+}
+
+func (s *BreakerSuite) TestHalfOpenProbesLateSuccess(t sweet.T) {
+	var (
+		clock   = glock.NewMockClock()
+		breaker = NewCircuitBreaker(
+			testConfig(),
+			withClock(clock),
+			WithHalfClosedProbes(1, 1),
+		)
+	)
+
+	for i := 0; i < 5; i++ {
+		Expect(breaker.Call(errFunc)).To(Equal(testErr))
+	}
+
+	Expect(breaker.ShouldTry()).To(BeFalse())
+	Expect(breaker.State()).To(Equal(StateOpen))
+
+	// A call admitted before the breaker opened is given to the
+	// trip condition, but does not close the breaker
+	breaker.MarkResult(nil)
+	Expect(breaker.Snapshot().TripCondition).To(Equal("0 of 5 consecutive failures"))
+	Expect(breaker.State()).To(Equal(StateOpen))
+	Expect(breaker.ShouldTry()).To(BeFalse())
+
+	clock.Advance(15 * time.Second)
+	Expect(breaker.ShouldTry()).To(BeTrue())
+	Expect(breaker.State()).To(Equal(StateHalfClosed))
+	breaker.MarkResult(nil)
+	Expect(breaker.State()).To(Equal(StateClosed))
+}
+
+func (s *BreakerSuite) TestHalfOpenProbesLateCall(t sweet.T) {
+	var (
+		clock   = glock.NewMockClock()
+		started = make(chan struct{})
+		block   = make(chan struct{})
+		errors  = make(chan error)
+		breaker = NewCircuitBreaker(
+			testConfig(),
+			withClock(clock),
+			WithInvocationTimeout(0),
+			WithHalfClosedProbes(1, 2),
+		)
+	)
+
+	go func() {
+		errors <- breaker.Call(func(ctx context.Context) error {
+			close(started)
+			<-block
+			return nil
+		})
+	}()
+
+	Eventually(started).Should(BeClosed())
+
+	for i := 0; i < 5; i++ {
+		Expect(breaker.Call(errFunc)).To(Equal(testErr))
+	}
+
+	Expect(breaker.ShouldTry()).To(BeFalse())
+	clock.Advance(15 * time.Second)
+	Expect(breaker.ShouldTry()).To(BeTrue())
+
+	// The call admitted while closed is not a probe
+	close(block)
+	Eventually(errors).Should(Receive(BeNil()))
+	Expect(breaker.ShouldTry()).To(BeFalse())
+	Expect(breaker.State()).To(Equal(StateHalfClosed))
+
+	breaker.MarkResult(nil)
+	Expect(breaker.State()).To(Equal(StateHalfClosed))
+	Expect(breaker.ShouldTry()).To(BeTrue())
+	breaker.MarkResult(nil)
+	Expect(breaker.State()).To(Equal(StateClosed))
+}
+
+func (s *BreakerSuite) TestHalfOpenProbeCancelled(t sweet.T) {
+	var (
+		clock   = glock.NewMockClock()
+		breaker = NewCircuitBreaker(
+			testConfig(),
+			withClock(clock),
+			WithHalfClosedProbes(1, 1),
+		)
+	)
+
+	for i := 0; i < 5; i++ {
+		Expect(breaker.Call(errFunc)).To(Equal(testErr))
+	}
+
+	Expect(breaker.ShouldTry()).To(BeFalse())
+	clock.Advance(15 * time.Second)
+	Expect(breaker.ShouldTry()).To(BeTrue())
+	Expect(breaker.ShouldTry()).To(BeFalse())
+
+	breaker.MarkResult(context.Canceled)
+	Expect(breaker.State()).To(Equal(StateHalfClosed))
+	Expect(breaker.Call(nilFunc)).To(BeNil())
+	Expect(breaker.State()).To(Equal(StateClosed))
+}
+
+func (s *BreakerSuite) TestDryRun(t sweet.T) {
+	var (
+		clock     = glock.NewMockClock()
+		collector = newTestCollector()
+		called    = 0
+		breaker   = NewCircuitBreaker(
+			testConfig(),
+			withClock(clock),
+			WithCollector(collector),
+			WithHalfClosedRetryProbability(1),
+			WithDryRun(),
+		)
+	)
+
+	fn := func(ctx context.Context) error {
+		called++
+		return nil
+	}
+
+	for i := 0; i < 5; i++ {
+		Expect(breaker.Call(errFunc)).To(Equal(testErr))
+	}
+
+	// Calls are made while open but do not close the breaker
+	for i := 0; i < 5; i++ {
+		Expect(breaker.Call(fn)).To(BeNil())
+		Expect(breaker.State()).To(Equal(StateOpen))
+	}
+
+	Expect(called).To(Equal(5))
+	Expect(collector.count(EventTypeWouldShortCircuit)).To(Equal(5))
+	Expect(collector.count(EventTypeShortCircuit)).To(Equal(0))
+
+	// Wait for retry backoff
+	clock.Advance(15 * time.Second)
+	Expect(breaker.Call(fn)).To(BeNil())
+	Expect(breaker.State()).To(Equal(StateClosed))
+	Expect(collector.count(EventTypeWouldShortCircuit)).To(Equal(5))
+}
+
+func (s *BreakerSuite) TestDryRunReportsResults(t sweet.T) {
+	var (
+		clock     = glock.NewMockClock()
+		collector = newTestCollector()
+		breaker   = NewCircuitBreaker(
+			testConfig(),
+			withClock(clock),
+			WithCollector(collector),
+			WithDryRun(),
+		)
+	)
+
+	for i := 0; i < 5; i++ {
+		Expect(breaker.Call(errFunc)).To(Equal(testErr))
+	}
+
+	Expect(collector.count(EventTypeError)).To(Equal(5))
+
+	// Shadow calls are classified as usual
+	Expect(breaker.Call(errFunc)).To(Equal(testErr))
+	Expect(breaker.Call(panicFunc)).To(HaveOccurred())
+	Expect(breaker.State()).To(Equal(StateOpen))
+	Expect(collector.count(EventTypeWouldShortCircuit)).To(Equal(2))
+	Expect(collector.count(EventTypeError)).To(Equal(7))
+	Expect(collector.count(EventTypePanic)).To(Equal(1))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	Expect(breaker.CallContext(ctx, func(ctx context.Context) error {
+		return ctx.Err()
+	})).To(Equal(context.Canceled))
+
+	Expect(collector.count(EventTypeCancelled)).To(Equal(1))
+
+	// Only the first five failures reached the trip condition
+	Expect(breaker.Snapshot().TripCondition).To(Equal("5 of 5 consecutive failures"))
+}
+
+func (s *BreakerSuite) TestCallAsync(t sweet.T) {
+	var (
+		breaker = NewCircuitBreaker(testConfig())
+		errors  = breaker.CallAsync(nilFunc)
+	)
+
+	Eventually(errors).Should(Receive(BeNil()))
+	Eventually(errors).Should(BeClosed())
+}
+
+func (s *BreakerSuite) TestCallAsyncNaturalError(t sweet.T) {
+	var (
+		breaker = NewCircuitBreaker(testConfig())
+		errors  = breaker.CallAsync(errFunc)
+	)
+
+	Eventually(errors).Should(Receive(Equal(testErr)))
+}
+
+func (s *BreakerSuite) TestCallAsyncNaturalErrorTrip(t sweet.T) {
+	breaker := NewCircuitBreaker(testConfig())
+
+	for i := 0; i < 5; i++ {
+		Expect(breaker.Call(errFunc)).To(Equal(testErr))
+	}
+
+	errors := breaker.CallAsync(errFunc)
+	Eventually(errors).Should(Receive(beError(ErrCircuitOpen)))
+}
+
+func (s *BreakerSuite) TestCallAsyncTimeout(t sweet.T) {
+	var (
+		clock   = glock.NewMockClock()
+		breaker = NewCircuitBreaker(testConfig(), withClock(clock))
+	)
+
+	go func() {
+		clock.BlockingAdvance(time.Minute)
+	}()
+
+	errors := breaker.CallAsync(blockingFunc)
+	Eventually(errors).Should(Receive(beError(ErrInvocationTimeout)))
+}
+
+func (s *BreakerSuite) TestMarkResultInvocationTimeout(t sweet.T) {
+	var (
+		called  = false
+		breaker = NewCircuitBreaker(
+			testConfig(),
+			WithFailureInterpreter(FailureInterpreterFunc(func(error) bool {
+				called = true
+				return true
+			})),
+		)
+	)
+
+	breaker.MarkResult(ErrInvocationTimeout)
+	Expect(called).To(BeFalse())
+}
+
+func (s *BreakerSuite) TestMarkResultCancelled(t sweet.T) {
+	var (
+		called  = false
+		breaker = NewCircuitBreaker(
+			testConfig(),
+			WithFailureInterpreter(FailureInterpreterFunc(func(error) bool {
+				called = true
+				return true
+			})),
+		)
+	)
+
+	for i := 0; i < 10; i++ {
+		breaker.MarkResult(fmt.Errorf("wrapped: %w", context.Canceled))
+	}
+
+	Expect(called).To(BeFalse())
+	Expect(breaker.ShouldTry()).To(BeTrue())
+}
+
+var (
+	TRIALS      = 50000
+	PROBAIBLITY = 0.25
+)
+
+func (s *BreakerSuite) TestHalfOpenProbability(t sweet.T) {
+	success := 0
+	failure := 0
+
+	for i := 0; i < TRIALS; i++ {
+		if runHalfOpenProbabilityTrial(PROBAIBLITY) {
+			success++
+		} else {
+			failure++
+		}
+	}
+
+	Expect(success + failure).To(Equal(TRIALS))
+	Expect(success).To(BeNumerically("~", success, float64(TRIALS)*PROBAIBLITY))
+}
+
+func runHalfOpenProbabilityTrial(probability float64) (called bool) {
+	var (
+		clock   = glock.NewMockClock()
+		breaker = NewCircuitBreaker(
+			testConfig(),
+			withClock(clock),
+			WithHalfClosedRetryProbability(probability),
+			WithTripCondition(NewConsecutiveFailureTripCondition(1)),
+		)
+	)
+
+	breaker.Call(errFunc)
+	clock.Advance(15 * time.Second)
+	return !errors.Is(breaker.Call(nilFunc), ErrCircuitOpen)
+}
+
+func (s *BreakerSuite) TestResetBackoff(t sweet.T) {
+	var (
+		clock   = glock.NewMockClock()
+		breaker = NewCircuitBreaker(
+			testConfig(),
+			withClock(clock),
+			WithHalfClosedRetryProbability(1),
+			WithResetBackoff(backoff.NewLinearBackoff(
+				100*time.Millisecond,
+				50*time.Millisecond,
+				time.Second,
+			)),
+		)
+	)
+
+	for i := 0; i < 2; i++ {
+		for j := 0; j < 5; j++ {
+			Expect(breaker.Call(errFunc)).To(Equal(testErr))
+		}
+
+		Expect(breaker.Call(errFunc)).To(beError(ErrCircuitOpen))
+		clock.Advance(100 * time.Millisecond)
+		Expect(breaker.Call(errFunc)).To(Equal(testErr))
+
+		Expect(breaker.Call(nilFunc)).To(beError(ErrCircuitOpen))
+		clock.Advance(150 * time.Millisecond)
+		Expect(breaker.Call(errFunc)).To(Equal(testErr))
+
+		Expect(breaker.Call(nilFunc)).To(beError(ErrCircuitOpen))
+		clock.Advance(200 * time.Millisecond)
+		Expect(breaker.Call(errFunc)).To(Equal(testErr))
+
+		Expect(breaker.Call(nilFunc)).To(beError(ErrCircuitOpen))
+		clock.Advance(250 * time.Millisecond)
+		Expect(breaker.Call(nilFunc)).To(BeNil())
+	}
+}
+
+func (s *BreakerSuite) TestHardTrip(t sweet.T) {
+	var (
+		clock   = glock.NewMockClock()
+		breaker = NewCircuitBreaker(testConfig(), withClock(clock))
+	)
+
+	Expect(breaker.Call(nilFunc)).To(BeNil())
+	breaker.Trip()
+
+	Expect(breaker.Call(nilFunc)).To(beError(ErrCircuitOpen))
+	clock.Advance(250 * time.Millisecond)
+	Expect(breaker.Call(nilFunc)).To(beError(ErrCircuitOpen))
+
+	breaker.Reset()
+	Expect(breaker.Call(nilFunc)).To(BeNil())
+}
+
+func (s *BreakerSuite) TestThreadPoolClose(t sweet.T) {
+	breaker := NewCircuitBreaker(
+		testConfig(),
+		WithThreadPool(1, 0, 0),
+	)
+
+	Expect(breaker.Call(nilFunc)).To(BeNil())
+	Expect(breaker.Close()).To(BeNil())
+	Expect(breaker.Call(nilFunc)).To(beError(ErrMaxConcurrency))
+}
+
+func (s *BreakerSuite) TestHardReset(t sweet.T) {
+	breaker := NewCircuitBreaker(testConfig())
+
+	for i := 0; i < 5; i++ {
+		Expect(breaker.Call(errFunc)).To(Equal(testErr))
+	}
+
+	Expect(breaker.Call(nilFunc)).To(beError(ErrCircuitOpen))
+	breaker.Reset()
+	Expect(breaker.Call(nilFunc)).To(BeNil())
+}
+
+func (s *BreakerSuite) TestStateChangeListener(t sweet.T) {
+	var (
+		clock   = glock.NewMockClock()
+		changes = []StateChange{}
+		breaker = NewCircuitBreaker(
+			testConfig(),
+			withClock(clock),
+			WithName("test"),
+			WithHalfClosedRetryProbability(1),
+			WithStateChangeListener(func(change StateChange) {
+				changes = append(changes, change)
+			}),
+		)
+	)
+
+	for i := 0; i < 5; i++ {
+		Expect(breaker.Call(errFunc)).To(Equal(testErr))
+	}
+
+	// Listeners are notified by the failure which trips the breaker
+	Expect(changes).To(HaveLen(1))
+
+	Expect(breaker.Call(errFunc)).To(beError(ErrCircuitOpen))
+	clock.Advance(15 * time.Second)
+	Expect(breaker.Call(errFunc)).To(Equal(testErr))
+	Expect(breaker.Call(nilFunc)).To(beError(ErrCircuitOpen))
+	clock.Advance(15 * time.Second)
+	Expect(breaker.Call(nilFunc)).To(BeNil())
+
+	breaker.Trip()
+	breaker.Reset()
+
+	Expect(changes).To(HaveLen(7))
+
+	for _, change := range changes {
+		Expect(change.Name).To(Equal("test"))
+	}
+
+	Expect(changes[0].From).To(Equal(StateClosed))
+	Expect(changes[0].To).To(Equal(StateOpen))
+	Expect(changes[0].Reason).To(Equal(StateChangeReasonTripped))
+	Expect(changes[1].From).To(Equal(StateOpen))
+	Expect(changes[1].To).To(Equal(StateHalfClosed))
+	Expect(changes[1].Reason).To(Equal(StateChangeReasonResetTimeoutElapsed))
+	Expect(changes[1].Time).To(Equal(clock.Now().Add(-15 * time.Second)))
+	Expect(changes[2].From).To(Equal(StateHalfClosed))
+	Expect(changes[2].To).To(Equal(StateOpen))
+	Expect(changes[2].Reason).To(Equal(StateChangeReasonProbeFailed))
+	Expect(changes[3].To).To(Equal(StateHalfClosed))
+	Expect(changes[4].From).To(Equal(StateHalfClosed))
+	Expect(changes[4].To).To(Equal(StateClosed))
+	Expect(changes[4].Reason).To(Equal(StateChangeReasonProbeSucceeded))
+	Expect(changes[5].To).To(Equal(StateHardOpen))
+	Expect(changes[5].Reason).To(Equal(StateChangeReasonManualTrip))
+	Expect(changes[6].From).To(Equal(StateHardOpen))
+	Expect(changes[6].To).To(Equal(StateClosed))
+	Expect(changes[6].Reason).To(Equal(StateChangeReasonManualReset))
+}
+
+func (s *BreakerSuite) TestStateChangeListenerQueriesBreaker(t sweet.T) {
+	var (
+		breaker CircuitBreaker
+		called  = false
+	)
+
+	breaker = NewCircuitBreaker(
+		testConfig(),
+		WithStateChangeListener(func(change StateChange) {
+			// Must not deadlock
+			Expect(breaker.ShouldTry()).To(BeFalse())
+			called = true
+		}),
+	)
+
+	breaker.Trip()
+	Expect(called).To(BeTrue())
+}
+
+func (s *BreakerSuite) TestSnapshot(t sweet.T) {
+	var (
+		clock   = glock.NewMockClock()
+		breaker = NewCircuitBreaker(testConfig(), withClock(clock), WithName("test"))
+	)
+
+	snapshot := breaker.Snapshot()
+	Expect(snapshot.Name).To(Equal("test"))
+	Expect(snapshot.State).To(Equal(StateClosed))
+	Expect(snapshot.LastFailureTime.IsZero()).To(BeTrue())
+	Expect(snapshot.NextAttemptTime.IsZero()).To(BeTrue())
+	Expect(snapshot.TripCondition).To(Equal("0 of 5 consecutive failures"))
+
+	for i := 0; i < 5; i++ {
+		Expect(breaker.Call(errFunc)).To(Equal(testErr))
+	}
+
+	// The failure which satisfies the trip condition opens the breaker
+	snapshot = breaker.Snapshot()
+	Expect(snapshot.State).To(Equal(StateOpen))
+	Expect(snapshot.LastFailureTime).To(Equal(clock.Now()))
+	Expect(snapshot.ResetTimeout).To(Equal(15 * time.Second))
+	Expect(snapshot.NextAttemptTime).To(Equal(clock.Now().Add(15 * time.Second)))
+	Expect(snapshot.TripCondition).To(Equal("5 of 5 consecutive failures"))
+
+	// Inspection must not cause a transition into half-closed
+	clock.Advance(time.Minute)
+	Expect(breaker.State()).To(Equal(StateOpen))
+	Expect(breaker.Snapshot().State).To(Equal(StateOpen))
+}
+
+//
+// Detailed in Issue #3
+//
+
+func (s *BreakerSuite) TestTripAfterSuccess(t sweet.T) {
+	var (
+		clock   = glock.NewMockClock()
+		breaker = NewCircuitBreaker(
+			testConfig(),
+			WithHalfClosedRetryProbability(1),
+			WithResetBackoff(backoff.NewConstantBackoff(time.Second)),
+			WithFailureInterpreter(NewAnyErrorFailureInterpreter()),
+			WithTripCondition(NewPercentageFailureTripCondition(100, 0.5)),
+			withClock(clock),
+		)
+	)
+
+	for i := 0; i < 40; i++ {
+		breaker.MarkResult(nil)
+	}
+
+	for i := 0; i < 60; i++ {
+		breaker.MarkResult(fmt.Errorf("utoh"))
+	}
+
+	Expect(breaker.ShouldTry()).To(BeFalse())
+	clock.Advance(time.Minute)
+	Expect(breaker.ShouldTry()).To(BeTrue())
+
+	// Before the bug was patched, it was possible to get into a situation
+	// where a nil result can cause the breaker to trip again, and the state
+	// of the breaker made it so that the condition which determined when the
+	// half-closed state was entered was indefinitely false.
+
+	for i := 0; i < 50; i++ {
+		breaker.MarkResult(nil)
+		Expect(breaker.ShouldTry()).To(BeFalse())
+		clock.Advance(time.Minute)
+		Expect(breaker.ShouldTry()).To(BeTrue())
+	}
+
+	breaker.MarkResult(nil)
+	Expect(breaker.ShouldTry()).To(BeTrue())
+	Expect(breaker.ShouldTry()).To(BeTrue())
+	Expect(breaker.ShouldTry()).To(BeTrue())
+}
+
+func (s *BreakerSuite) TestTimeoutZeroTimeout(t sweet.T) {
+	fn := func(ctx context.Context) error {
+		return nil
+	}
+
+	Expect(callWithTimeout(context.Background(), fn, nil, 0)).To(BeNil())
+}
+
+func (s *BreakerSuite) TestTimeoutNoError(t sweet.T) {
+	var (
+		clock = glock.NewMockClock()
+		fn    = func(ctx context.Context) error {
+			return nil
+		}
+	)
+
+	Expect(callWithTimeout(context.Background(), fn, clock, time.Minute)).To(BeNil())
+
+	args := clock.GetAfterArgs()
+	Expect(args).To(HaveLen(1))
+	Expect(args[0]).To(Equal(time.Minute))
+}
+
+func (s *BreakerSuite) TestTimeoutError(t sweet.T) {
+	var (
+		clock = glock.NewMockClock()
+		fn    = func(ctx context.Context) error {
+			return errors.New("utoh")
+		}
+	)
+
+	Expect(callWithTimeout(context.Background(), fn, clock, time.Minute)).To(MatchError("utoh"))
+
+	args := clock.GetAfterArgs()
+	Expect(args).To(HaveLen(1))
+	Expect(args[0]).To(Equal(time.Minute))
+}
+
+func (s *BreakerSuite) TestTimeoutTimeout(t sweet.T) {
+	var (
+		clock  = glock.NewMockClock()
+		sync   = make(chan struct{})
+		errors = make(chan error)
+		fn     = func(ctx context.Context) error {
+			defer close(sync)
+			<-ctx.Done()
+			return nil
+		}
+	)
+
+	go func() {
+		defer close(errors)
+		errors <- callWithTimeout(context.Background(), fn, clock, time.Minute)
+	}()
+
+	Consistently(sync).ShouldNot(Receive())
+	Consistently(errors).ShouldNot(Receive())
+	clock.Advance(time.Minute * 2)
+	Eventually(errors).Should(Receive(beError(ErrInvocationTimeout)))
+	Eventually(sync).Should(BeClosed())
+}
+
+func (s *BreakerSuite) TestTimeoutCancel(t sweet.T) {
+	var (
+		clock       = glock.NewMockClock()
+		sync        = make(chan struct{})
+		errors      = make(chan error)
+		ctx, cancel = context.WithCancel(context.Background())
+		fn          = func(ctx context.Context) error {
+			defer close(sync)
+			<-ctx.Done()
+			return nil
+		}
+	)
+
+	go func() {
+		defer close(errors)
+		errors <- callWithTimeout(ctx, fn, clock, time.Minute)
+	}()
+
+	Consistently(sync).ShouldNot(Receive())
+	Consistently(errors).ShouldNot(Receive())
+	cancel()
+	Eventually(errors).Should(Receive(Equal(context.Canceled)))
+	Eventually(sync).Should(BeClosed())
+}
+
+func (s *BreakerSuite) TestTimeoutObservedByFunction(t sweet.T) {
+	var (
+		clock  = glock.NewMockClock()
+		errors = make(chan error)
+		fn     = func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+	)
+
+	go func() {
+		defer close(errors)
+		errors <- callWithTimeout(context.Background(), fn, clock, time.Minute)
+	}()
+
+	Consistently(errors).ShouldNot(Receive())
+	clock.Advance(time.Minute)
+	Eventually(errors).Should(Receive(beError(ErrInvocationTimeout)))
+}
+
+func (s *BreakerSuite) TestSlowCallTrip(t sweet.T) {
+	var (
+		clock   = glock.NewMockClock()
+		breaker = NewCircuitBreaker(
+			testConfig(),
+			WithInvocationTimeout(0),
+			WithTripCondition(NewSlowCallRateTripCondition(time.Minute, time.Second, 0.5, 4)),
+			withClock(clock),
+		)
+	)
+
+	slowFunc := func(ctx context.Context) error {
+		clock.Advance(time.Second * 2)
+		return nil
+	}
+
+	for i := 0; i < 2; i++ {
+		Expect(breaker.Call(nilFunc)).To(BeNil())
+	}
+
+	Expect(breaker.Call(slowFunc)).To(BeNil())
+	Expect(breaker.State()).To(Equal(StateClosed))
+	Expect(breaker.Call(slowFunc)).To(BeNil())
+	Expect(breaker.Call(nilFunc)).To(beError(ErrCircuitOpen))
+}
+
+func (s *BreakerSuite) TestMarkResultUnknownDuration(t sweet.T) {
+	breaker := NewCircuitBreaker(
+		testConfig(),
+		WithTripCondition(NewSlowCallRateTripCondition(time.Minute, time.Second, 0.5, 1)),
+	)
+
+	Expect(breaker.MarkResult(nil)).To(BeTrue())
+	Expect(breaker.MarkResult(testErr)).To(BeFalse())
+	Expect(breaker.ShouldTry()).To(BeTrue())
+}
+
+func (s *BreakerSuite) TestRollingErrorPercentageConfig(t sweet.T) {
+	collector := newTestCollector()
+
+	NewCircuitBreaker(
+		testConfig(),
+		WithCollector(collector),
+		WithTripCondition(NewRollingErrorPercentageTripCondition(10*time.Second, 10, 0.5, 20)),
+	)
+
+	Expect(collector.configs).To(ConsistOf(BreakerConfig{
+		MaxConcurrency:           100,
+		ErrorThresholdPercentage: 50,
+		RequestVolumeThreshold:   20,
+	}))
+}
+
+func (s *BreakerSuite) TestRollingErrorPercentageConfigRounding(t sweet.T) {
+	collector := newTestCollector()
+
+	// 0.29 * 100 is slightly less than 29 in floating point
+	NewCircuitBreaker(
+		testConfig(),
+		WithCollector(collector),
+		WithTripCondition(NewRollingErrorPercentageTripCondition(10*time.Second, 10, 0.29, 20)),
+	)
+
+	Expect(collector.configs).To(ConsistOf(BreakerConfig{
+		MaxConcurrency:           100,
+		ErrorThresholdPercentage: 29,
+		RequestVolumeThreshold:   20,
+	}))
+}
+
+func (s *BreakerSuite) TestCircuitOpenError(t sweet.T) {
+	var (
+		clock   = glock.NewMockClock()
+		breaker = NewCircuitBreaker(
+			testConfig(),
+			WithName("test"),
+			WithTripCondition(NewConsecutiveFailureTripCondition(1)),
+			withClock(clock),
+		)
+	)
+
+	Expect(breaker.Call(errFunc)).To(Equal(testErr))
+	clock.Advance(5 * time.Second)
+
+	err := breaker.Call(nilFunc)
+	Expect(err).To(beError(ErrCircuitOpen))
+	Expect(err).To(Equal(&CircuitOpenError{
+		Name:       "test",
+		State:      StateOpen,
+		RetryAfter: 10 * time.Second,
+	}))
+
+	breaker.Trip()
+	Expect(breaker.Call(nilFunc)).To(Equal(&CircuitOpenError{
+		Name:  "test",
+		State: StateHardOpen,
+	}))
+}
+
+func (s *BreakerSuite) TestTimeoutErrorDetails(t sweet.T) {
+	var (
+		clock   = glock.NewMockClock()
+		breaker = NewCircuitBreaker(testConfig(), WithName("test"), withClock(clock))
+	)
+
+	go func() {
+		clock.BlockingAdvance(time.Minute)
+	}()
+
+	err := breaker.Call(blockingFunc)
+	Expect(err).To(beError(ErrInvocationTimeout))
+	Expect(err).To(beError(context.DeadlineExceeded))
+	Expect(err).To(Equal(&TimeoutError{Name: "test", Timeout: time.Minute}))
+}
+
+func (s *BreakerSuite) TestRetryAfter(t sweet.T) {
+	var (
+		clock   = glock.NewMockClock()
+		breaker = NewCircuitBreaker(
+			testConfig(),
+			WithTripCondition(NewConsecutiveFailureTripCondition(1)),
+			withClock(clock),
+		)
+	)
+
+	Expect(breaker.Call(retryAfterFunc(time.Minute))).To(HaveOccurred())
+	Expect(breaker.Call(nilFunc)).To(Equal(&CircuitOpenError{State: StateOpen, RetryAfter: time.Minute}))
+	Expect(breaker.Snapshot().ResetTimeout).To(Equal(time.Minute))
+
+	// Uses the reset backoff again once closed
+	breaker.Reset()
+	Expect(breaker.Call(errFunc)).To(Equal(testErr))
+	Expect(breaker.ShouldTry()).To(BeFalse())
+	Expect(breaker.Snapshot().ResetTimeout).To(Equal(15 * time.Second))
+}
+
+func (s *BreakerSuite) TestRetryAfterBounds(t sweet.T) {
+	var (
+		clock   = glock.NewMockClock()
+		breaker = NewCircuitBreaker(
+			testConfig(),
+			WithTripCondition(NewConsecutiveFailureTripCondition(1)),
+			WithRetryAfterBounds(5*time.Second, 30*time.Second),
+			withClock(clock),
+		)
+	)
+
+	Expect(breaker.Call(retryAfterFunc(time.Minute))).To(HaveOccurred())
+	Expect(breaker.ShouldTry()).To(BeFalse())
+	Expect(breaker.Snapshot().ResetTimeout).To(Equal(30 * time.Second))
+
+	breaker.Reset()
+	Expect(breaker.Call(retryAfterFunc(time.Second))).To(HaveOccurred())
+	Expect(breaker.ShouldTry()).To(BeFalse())
+	Expect(breaker.Snapshot().ResetTimeout).To(Equal(5 * time.Second))
+}
+
+func (s *BreakerSuite) TestRetryAfterProbeFailure(t sweet.T) {
+	var (
+		clock   = glock.NewMockClock()
+		breaker = NewCircuitBreaker(
+			testConfig(),
+			WithTripCondition(NewConsecutiveFailureTripCondition(1)),
+			WithHalfClosedProbes(1, 1),
+			withClock(clock),
+		)
+	)
+
+	Expect(breaker.Call(errFunc)).To(Equal(testErr))
+	Expect(breaker.ShouldTry()).To(BeFalse())
+	clock.Advance(15 * time.Second)
+
+	Expect(breaker.Call(retryAfterFunc(time.Minute))).To(HaveOccurred())
+	Expect(breaker.State()).To(Equal(StateOpen))
+	Expect(breaker.Snapshot().ResetTimeout).To(Equal(time.Minute))
+}
 
-	// Wait for retry backoff
-	clock.Advance(15 * time.Second)
+func (s *BreakerSuite) TestRetryAfterIgnoredForBadRequest(t sweet.T) {
+	breaker := NewCircuitBreaker(
+		testConfig(),
+		WithTripCondition(NewConsecutiveFailureTripCondition(1)),
+		WithFailureInterpreter(NewErrorIsFailureInterpreter(testErr)),
+	)
+
+	Expect(breaker.Call(retryAfterFunc(time.Minute))).To(HaveOccurred())
 	Expect(breaker.Call(errFunc)).To(Equal(testErr))
-	Expect(breaker.Call(nilFunc)).To(Equal(ErrCircuitOpen))
+	Expect(breaker.ShouldTry()).To(BeFalse())
+	Expect(breaker.Snapshot().ResetTimeout).To(Equal(15 * time.Second))
 }
 
-func (s *BreakerSuite) TestHalfOpenReset(t sweet.T) {
+func (s *BreakerSuite) TestAdaptiveInvocationTimeout(t sweet.T) {
 	var (
-		clock   = glock.NewMockClock()
-		breaker = NewCircuitBreaker(
+		clock     = glock.NewMockClock()
+		collector = newTestCollector()
+		breaker   = NewCircuitBreaker(
 			testConfig(),
+			WithName("test"),
+			WithCollector(collector),
+			WithAdaptiveInvocationTimeout(4, 0.5, 2, 10*time.Millisecond, time.Minute),
 			withClock(clock),
-			WithHalfClosedRetryProbability(1),
 		)
 	)
 
-	for i := 0; i < 5; i++ {
-		Expect(breaker.Call(errFunc)).To(Equal(testErr))
+	for i := 0; i < 4; i++ {
+		Expect(breaker.Call(func(ctx context.Context) error {
+			clock.Advance(100 * time.Millisecond)
+			return nil
+		})).To(BeNil())
 	}
 
-	Expect(breaker.Call(errFunc)).To(Equal(ErrCircuitOpen))
+	Expect(collector.durations(EventTypeInvocationTimeout)).To(Equal([]time.Duration{
+		time.Minute,
+		200 * time.Millisecond,
+	}))
 
-	// Wait for retry backoff
-	clock.Advance(15 * time.Second)
-	Expect(breaker.Call(nilFunc)).To(BeNil())
+	go func() {
+		clock.BlockingAdvance(200 * time.Millisecond)
+	}()
+
+	Expect(breaker.Call(blockingFunc)).To(Equal(&TimeoutError{Name: "test", Timeout: 200 * time.Millisecond}))
 }
 
-func (s *BreakerSuite) TestCallAsync(t sweet.T) {
+func (s *BreakerSuite) TestRetry(t sweet.T) {
 	var (
-		breaker = NewCircuitBreaker(testConfig())
-		errors  = breaker.CallAsync(nilFunc)
+		collector = newTestCollector()
+		attempts  = 0
+		breaker   = NewCircuitBreaker(
+			testConfig(),
+			WithCollector(collector),
+			WithRetry(backoff.NewConstantBackoff(time.Millisecond), 3, nil),
+		)
 	)
 
-	Eventually(errors).Should(Receive(BeNil()))
-	Eventually(errors).Should(BeClosed())
+	Expect(breaker.Call(func(ctx context.Context) error {
+		if attempts++; attempts < 3 {
+			return testErr
+		}
+
+		return nil
+	})).To(BeNil())
+
+	Expect(attempts).To(Equal(3))
+	Expect(collector.count(EventTypeRetry)).To(Equal(2))
 }
 
-func (s *BreakerSuite) TestCallAsyncNaturalError(t sweet.T) {
-	var (
-		breaker = NewCircuitBreaker(testConfig())
-		errors  = breaker.CallAsync(errFunc)
+func (s *BreakerSuite) TestRetryNilBackoff(t sweet.T) {
+	attempts := 0
+	breaker := NewCircuitBreaker(
+		testConfig(),
+		WithRetry(nil, 3, nil),
 	)
 
-	Eventually(errors).Should(Receive(Equal(testErr)))
+	Expect(breaker.Call(func(ctx context.Context) error {
+		attempts++
+		return testErr
+	})).To(Equal(testErr))
+
+	Expect(attempts).To(Equal(3))
 }
 
-func (s *BreakerSuite) TestCallAsyncNaturalErrorTrip(t sweet.T) {
-	breaker := NewCircuitBreaker(testConfig())
+func (s *BreakerSuite) TestRetryMaxAttempts(t sweet.T) {
+	attempts := 0
+	breaker := NewCircuitBreaker(
+		testConfig(),
+		WithRetry(backoff.NewConstantBackoff(time.Millisecond), 3, nil),
+	)
 
-	for i := 0; i < 5; i++ {
-		Expect(breaker.Call(errFunc)).To(Equal(testErr))
-	}
+	Expect(breaker.Call(func(ctx context.Context) error {
+		attempts++
+		return testErr
+	})).To(Equal(testErr))
 
-	errors := breaker.CallAsync(errFunc)
-	Eventually(errors).Should(Receive(Equal(ErrCircuitOpen)))
+	Expect(attempts).To(Equal(3))
 }
 
-func (s *BreakerSuite) TestCallAsyncTimeout(t sweet.T) {
-	var (
-		clock   = glock.NewMockClock()
-		breaker = NewCircuitBreaker(testConfig(), withClock(clock))
+func (s *BreakerSuite) TestRetryNotRetryable(t sweet.T) {
+	attempts := 0
+	breaker := NewCircuitBreaker(
+		testConfig(),
+		WithRetry(backoff.NewConstantBackoff(time.Millisecond), 3, NewErrorIsFailureInterpreter(context.DeadlineExceeded)),
 	)
 
-	go func() {
-		clock.BlockingAdvance(time.Minute)
-	}()
+	Expect(breaker.Call(func(ctx context.Context) error {
+		attempts++
+		return testErr
+	})).To(Equal(testErr))
 
-	errors := breaker.CallAsync(blockingFunc)
-	Eventually(errors).Should(Receive(Equal(ErrInvocationTimeout)))
+	Expect(attempts).To(Equal(1))
 }
 
-func (s *BreakerSuite) TestMarkResultInvocationTimeout(t sweet.T) {
-	var (
-		called  = false
-		breaker = NewCircuitBreaker(
-			testConfig(),
-			WithFailureInterpreter(FailureInterpreterFunc(func(error) bool {
-				called = true
-				return true
-			})),
-		)
+func (s *BreakerSuite) TestRetryCircuitOpen(t sweet.T) {
+	attempts := 0
+	breaker := NewCircuitBreaker(
+		testConfig(),
+		WithTripCondition(NewConsecutiveFailureTripCondition(2)),
+		WithRetry(backoff.NewConstantBackoff(time.Millisecond), 5, nil),
 	)
 
-	breaker.MarkResult(ErrInvocationTimeout)
-	Expect(called).To(BeFalse())
+	Expect(breaker.Call(func(ctx context.Context) error {
+		attempts++
+		return testErr
+	})).To(beError(ErrCircuitOpen))
+
+	Expect(attempts).To(Equal(2))
 }
 
-var (
-	TRIALS      = 50000
-	PROBAIBLITY = 0.25
-)
+func (s *BreakerSuite) TestRetryDeadline(t sweet.T) {
+	attempts := 0
+	breaker := NewCircuitBreaker(
+		testConfig(),
+		WithRetry(backoff.NewConstantBackoff(time.Minute), 3, nil),
+	)
 
-func (s *BreakerSuite) TestHalfOpenProbability(t sweet.T) {
-	success := 0
-	failure := 0
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
 
-	for i := 0; i < TRIALS; i++ {
-		if runHalfOpenProbabilityTrial(PROBAIBLITY) {
-			success++
-		} else {
-			failure++
-		}
-	}
+	Expect(breaker.CallContext(ctx, func(ctx context.Context) error {
+		attempts++
+		return testErr
+	})).To(Equal(testErr))
 
-	Expect(success + failure).To(Equal(TRIALS))
-	Expect(success).To(BeNumerically("~", success, float64(TRIALS)*PROBAIBLITY))
+	Expect(attempts).To(Equal(1))
 }
 
-func runHalfOpenProbabilityTrial(probability float64) (called bool) {
+func (s *BreakerSuite) TestRetryCancel(t sweet.T) {
 	var (
-		clock   = glock.NewMockClock()
-		breaker = NewCircuitBreaker(
+		ctx, cancel = context.WithCancel(context.Background())
+		errors      = make(chan error)
+		breaker     = NewCircuitBreaker(
 			testConfig(),
-			withClock(clock),
-			WithHalfClosedRetryProbability(probability),
-			WithTripCondition(NewConsecutiveFailureTripCondition(1)),
+			WithRetry(backoff.NewConstantBackoff(time.Minute), 3, nil),
 		)
 	)
 
-	breaker.Call(errFunc)
-	clock.Advance(15 * time.Second)
-	return breaker.Call(nilFunc) != ErrCircuitOpen
+	go func() {
+		defer close(errors)
+		errors <- breaker.CallContext(ctx, errFunc)
+	}()
+
+	Consistently(errors).ShouldNot(Receive())
+	cancel()
+	Eventually(errors).Should(Receive(Equal(context.Canceled)))
 }
 
-func (s *BreakerSuite) TestResetBackoff(t sweet.T) {
+func (s *BreakerSuite) TestRetryDeadlineDuringBackoff(t sweet.T) {
 	var (
-		clock   = glock.NewMockClock()
-		breaker = NewCircuitBreaker(
+		clock     = glock.NewMockClockAt(time.Time{})
+		collector = newTestCollector()
+		breaker   = NewCircuitBreaker(
 			testConfig(),
+			WithInvocationTimeout(0),
+			WithRetry(backoff.NewConstantBackoff(time.Second), 3, nil),
+			WithCollector(collector),
 			withClock(clock),
-			WithHalfClosedRetryProbability(1),
-			WithResetBackoff(backoff.NewLinearBackoff(
-				100*time.Millisecond,
-				50*time.Millisecond,
-				time.Second,
-			)),
 		)
 	)
 
-	for i := 0; i < 2; i++ {
-		for j := 0; j < 5; j++ {
-			Expect(breaker.Call(errFunc)).To(Equal(testErr))
-		}
-
-		Expect(breaker.Call(errFunc)).To(Equal(ErrCircuitOpen))
-		clock.Advance(100 * time.Millisecond)
-		Expect(breaker.Call(errFunc)).To(Equal(testErr))
-
-		Expect(breaker.Call(nilFunc)).To(Equal(ErrCircuitOpen))
-		clock.Advance(150 * time.Millisecond)
-		Expect(breaker.Call(errFunc)).To(Equal(testErr))
-
-		Expect(breaker.Call(nilFunc)).To(Equal(ErrCircuitOpen))
-		clock.Advance(200 * time.Millisecond)
-		Expect(breaker.Call(errFunc)).To(Equal(testErr))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
 
-		Expect(breaker.Call(nilFunc)).To(Equal(ErrCircuitOpen))
-		clock.Advance(250 * time.Millisecond)
-		Expect(breaker.Call(nilFunc)).To(BeNil())
-	}
+	Expect(breaker.CallContext(ctx, errFunc)).To(Equal(testErr))
+	Expect(collector.count(EventTypeCancelled)).To(Equal(0))
 }
 
-func (s *BreakerSuite) TestHardTrip(t sweet.T) {
+func (s *BreakerSuite) TestRetryBudget(t sweet.T) {
 	var (
-		clock   = glock.NewMockClock()
-		breaker = NewCircuitBreaker(testConfig(), withClock(clock))
+		collector = newTestCollector()
+		budget    = NewRetryBudget(0.5, 0, time.Minute)
+		attempts  = 0
+		breaker   = NewCircuitBreaker(
+			testConfig(),
+			WithCollector(collector),
+			WithRetry(backoff.NewConstantBackoff(time.Millisecond), 3, nil),
+			WithRetryBudget(budget),
+		)
 	)
 
-	Expect(breaker.Call(nilFunc)).To(BeNil())
-	breaker.Trip()
+	failing := func(ctx context.Context) error {
+		attempts++
+		return testErr
+	}
 
-	Expect(breaker.Call(nilFunc)).To(Equal(ErrCircuitOpen))
-	clock.Advance(250 * time.Millisecond)
-	Expect(breaker.Call(nilFunc)).To(Equal(ErrCircuitOpen))
+	err := breaker.Call(failing)
+	Expect(err).To(beError(ErrRetryBudgetExhausted))
+	Expect(err).To(beError(testErr))
+	Expect(err).To(Equal(&RetryBudgetError{Err: testErr}))
+	Expect(attempts).To(Equal(1))
+	Expect(collector.count(EventTypeRetryBudgetExhausted)).To(Equal(1))
 
-	breaker.Reset()
+	// Successful first attempts fund retries
 	Expect(breaker.Call(nilFunc)).To(BeNil())
+	Expect(breaker.Call(nilFunc)).To(BeNil())
+
+	attempts = 0
+	Expect(breaker.Call(failing)).To(beError(ErrRetryBudgetExhausted))
+	Expect(attempts).To(Equal(2))
+	Expect(collector.count(EventTypeRetry)).To(Equal(1))
 }
 
-func (s *BreakerSuite) TestHardReset(t sweet.T) {
-	breaker := NewCircuitBreaker(testConfig())
+func (s *BreakerSuite) TestThreadPool(t sweet.T) {
+	var (
+		collector = newTestCollector()
+		block     = make(chan struct{})
+		errors    = make(chan error, 2)
+		breaker   = NewCircuitBreaker(
+			testConfig(),
+			WithName("test"),
+			WithCollector(collector),
+			WithThreadPool(1, 1, 0),
+		)
+	)
 
-	for i := 0; i < 5; i++ {
-		Expect(breaker.Call(errFunc)).To(Equal(testErr))
+	blocking := func(ctx context.Context) error {
+		<-block
+		return nil
 	}
 
-	Expect(breaker.Call(nilFunc)).To(Equal(ErrCircuitOpen))
-	breaker.Reset()
+	go func() { errors <- breaker.Call(blocking) }()
+	Eventually(func() int { return collector.count(EventTypeSemaphoreAcquired) }).Should(Equal(1))
+	go func() { errors <- breaker.Call(blocking) }()
+	Eventually(func() int { return collector.count(EventTypeSemaphoreQueued) }).Should(Equal(1))
+
+	Expect(breaker.Call(nilFunc)).To(Equal(&MaxConcurrencyError{Name: "test", MaxConcurrency: 1}))
+	Expect(collector.count(EventTypeRejection)).To(Equal(1))
+	Expect(breaker.State()).To(Equal(StateClosed))
+
+	close(block)
+	Eventually(errors).Should(Receive(BeNil()))
+	Eventually(errors).Should(Receive(BeNil()))
 	Expect(breaker.Call(nilFunc)).To(BeNil())
+	Expect(collector.count(EventTypePoolTaskCompleted)).To(Equal(3))
 }
 
-//
-// Detailed in Issue #3
-//
-
-func (s *BreakerSuite) TestTripAfterSuccess(t sweet.T) {
+func (s *BreakerSuite) TestThreadPoolTimeout(t sweet.T) {
 	var (
 		clock   = glock.NewMockClock()
 		breaker = NewCircuitBreaker(
 			testConfig(),
-			WithHalfClosedRetryProbability(1),
-			WithResetBackoff(backoff.NewConstantBackoff(time.Second)),
-			WithFailureInterpreter(NewAnyErrorFailureInterpreter()),
-			WithTripCondition(NewPercentageFailureTripCondition(100, 0.5)),
+			WithThreadPool(1, 0, 0),
 			withClock(clock),
 		)
 	)
 
-	for i := 0; i < 40; i++ {
-		breaker.MarkResult(nil)
-	}
+	go func() {
+		clock.BlockingAdvance(time.Minute)
+	}()
 
-	for i := 0; i < 60; i++ {
-		breaker.MarkResult(fmt.Errorf("utoh"))
-	}
+	Expect(breaker.Call(blockingFunc)).To(beError(ErrInvocationTimeout))
 
-	Expect(breaker.ShouldTry()).To(BeFalse())
-	clock.Advance(time.Minute)
-	Expect(breaker.ShouldTry()).To(BeTrue())
+	// The worker is still busy with the abandoned call
+	Expect(breaker.Call(nilFunc)).To(beError(ErrMaxConcurrency))
+}
 
-	// Before the bug was patched, it was possible to get into a situation
-	// where a nil result can cause the breaker to trip again, and the state
-	// of the breaker made it so that the condition which determined when the
-	// half-closed state was entered was indefinitely false.
+func (s *BreakerSuite) TestThreadPoolQueuedTimeout(t sweet.T) {
+	var (
+		collector = newTestCollector()
+		block     = make(chan struct{})
+		errors    = make(chan error, 1)
+		calls     = int32(0)
+		breaker   = NewCircuitBreaker(
+			testConfig(),
+			WithInvocationTimeout(0),
+			WithCollector(collector),
+			WithThreadPool(1, 1, 0),
+		)
+	)
 
-	for i := 0; i < 50; i++ {
-		breaker.MarkResult(nil)
-		Expect(breaker.ShouldTry()).To(BeFalse())
-		clock.Advance(time.Minute)
-		Expect(breaker.ShouldTry()).To(BeTrue())
-	}
+	go func() {
+		errors <- breaker.Call(func(ctx context.Context) error {
+			<-block
+			return nil
+		})
+	}()
 
-	breaker.MarkResult(nil)
-	Expect(breaker.ShouldTry()).To(BeTrue())
-	Expect(breaker.ShouldTry()).To(BeTrue())
-	Expect(breaker.ShouldTry()).To(BeTrue())
-}
+	Eventually(func() int { return collector.count(EventTypeSemaphoreAcquired) }).Should(Equal(1))
 
-func (s *BreakerSuite) TestTimeoutZeroTimeout(t sweet.T) {
-	fn := func(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+
+	Expect(breaker.CallContext(ctx, func(ctx context.Context) error {
+		atomic.AddInt32(&calls, 1)
 		return nil
-	}
+	})).To(beError(ErrInvocationTimeout))
+
+	close(block)
+	Eventually(errors).Should(Receive(BeNil()))
+	Expect(breaker.Call(nilFunc)).To(BeNil())
 
-	Expect(callWithTimeout(fn, nil, 0)).To(BeNil())
+	// The call which timed out while queued is never run or counted as abandoned
+	Expect(atomic.LoadInt32(&calls)).To(Equal(int32(0)))
+	Expect(breaker.Snapshot().Abandoned).To(Equal(0))
+	Expect(collector.count(EventTypeAbandoned)).To(Equal(0))
 }
 
-func (s *BreakerSuite) TestTimeoutNoError(t sweet.T) {
-	var (
-		clock = glock.NewMockClock()
-		fn    = func(ctx context.Context) error {
-			return nil
-		}
+func (s *BreakerSuite) TestThreadPoolShortCircuit(t sweet.T) {
+	breaker := NewCircuitBreaker(
+		testConfig(),
+		WithThreadPool(1, 0, 0),
+		WithTripCondition(NewConsecutiveFailureTripCondition(1)),
 	)
 
-	Expect(callWithTimeout(fn, clock, time.Minute)).To(BeNil())
+	Expect(breaker.Call(errFunc)).To(Equal(testErr))
 
-	args := clock.GetAfterArgs()
-	Expect(args).To(HaveLen(1))
-	Expect(args[0]).To(Equal(time.Minute))
+	// Short-circuited calls release their reservation
+	for i := 0; i < 3; i++ {
+		Expect(breaker.Call(nilFunc)).To(beError(ErrCircuitOpen))
+	}
+
+	breaker.Reset()
+	Expect(breaker.Call(nilFunc)).To(BeNil())
 }
 
-func (s *BreakerSuite) TestTimeoutError(t sweet.T) {
+func (s *BreakerSuite) TestAdaptiveThrottling(t sweet.T) {
 	var (
-		clock = glock.NewMockClock()
-		fn    = func(ctx context.Context) error {
-			return errors.New("utoh")
-		}
+		collector = newTestCollector()
+		breaker   = NewCircuitBreaker(
+			testConfig(),
+			WithCollector(collector),
+			WithInvocationTimeout(0),
+			WithTripCondition(NewConsecutiveFailureTripCondition(1000)),
+			WithAdaptiveThrottling(2, time.Minute),
+		)
 	)
 
-	Expect(callWithTimeout(fn, clock, time.Minute)).To(MatchError("utoh"))
+	for i := 0; i < 100; i++ {
+		Expect(breaker.Call(nilFunc)).To(BeNil())
+	}
 
-	args := clock.GetAfterArgs()
-	Expect(args).To(HaveLen(1))
-	Expect(args[0]).To(Equal(time.Minute))
-}
+	Expect(collector.gauges(EventTypeRejectProbability)).To(Equal([]float64{0}))
 
-func (s *BreakerSuite) TestTimeoutTimeout(t sweet.T) {
-	var (
-		clock  = glock.NewMockClock()
-		sync   = make(chan struct{})
-		errors = make(chan error)
-		fn     = func(ctx context.Context) error {
-			defer close(sync)
-			<-ctx.Done()
-			return nil
+	rejected := 0
+	for i := 0; i < 500; i++ {
+		if errors.Is(breaker.Call(errFunc), ErrCircuitOpen) {
+			rejected++
 		}
-	)
+	}
 
-	go func() {
-		defer close(errors)
-		errors <- callWithTimeout(fn, clock, time.Minute)
-	}()
+	// Calls are shed in proportion to the acceptance rate without opening the circuit
+	Expect(rejected).To(BeNumerically(">", 0))
+	Expect(rejected).To(BeNumerically("<", 500))
+	Expect(collector.count(EventTypeShortCircuit)).To(Equal(rejected))
+	Expect(breaker.State()).To(Equal(StateClosed))
 
-	Consistently(sync).ShouldNot(Receive())
-	Consistently(errors).ShouldNot(Receive())
-	clock.Advance(time.Minute * 2)
-	Eventually(errors).Should(Receive(Equal(ErrInvocationTimeout)))
-	Eventually(sync).Should(BeClosed())
+	gauges := collector.gauges(EventTypeRejectProbability)
+	Expect(gauges[len(gauges)-1]).To(BeNumerically(">", 0.5))
 }
 
 //
@@ -415,11 +1615,36 @@ func errFunc(ctx context.Context) error {
 	return testErr
 }
 
+func panicFunc(ctx context.Context) error {
+	panic("utoh")
+}
+
 func blockingFunc(ctx context.Context) error {
 	<-make(chan struct{})
 	return nil
 }
 
+type testRetryAfterError time.Duration
+
+func (e testRetryAfterError) Error() string {
+	return "retry later"
+}
+
+func (e testRetryAfterError) RetryAfter() time.Duration {
+	return time.Duration(e)
+}
+
+func retryAfterFunc(retryAfter time.Duration) BreakerFunc {
+	return func(ctx context.Context) error {
+		return fmt.Errorf("wrapped: %w", testRetryAfterError(retryAfter))
+	}
+}
+
+// beError matches errors which match the given target via errors.Is.
+func beError(target error) types.GomegaMatcher {
+	return WithTransform(func(err error) bool { return errors.Is(err, target) }, BeTrue())
+}
+
 func testConfig() BreakerConfigFunc {
 	return func(cb *circuitBreaker) {
 		cb.invocationTimeout = time.Minute
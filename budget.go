@@ -0,0 +1,75 @@
+package overcurrent
+
+import (
+	"sync"
+	"time"
+
+	"github.com/efritz/glock"
+)
+
+type (
+	// RetryBudget limits the number of retries made within a rolling window to a
+	// ratio of the number of first attempts which succeeded within the window, plus
+	// a minimum number of retries per second. A budget may be shared by several
+	// breakers (e.g. by passing WithRetryBudget to WithBreakerDefaults).
+	RetryBudget struct {
+		mutex        sync.Mutex
+		clock        glock.Clock
+		attempts     rollingWindow
+		ratio        float64
+		minPerSecond float64
+	}
+)
+
+const retryBudgetBuckets = 10
+
+// Counters of the rolling window of a RetryBudget.
+const (
+	budgetDeposits = iota
+	budgetRetries
+)
+
+// NewRetryBudget creates a RetryBudget. Within the window, the number of retries
+// may not exceed ratio times the number of successful first attempts plus
+// minPerSecond times the length of the window in seconds. A window shorter than
+// ten nanoseconds is clamped to ten nanoseconds.
+func NewRetryBudget(ratio, minPerSecond float64, window time.Duration) *RetryBudget {
+	return newRetryBudgetWithClock(ratio, minPerSecond, window, glock.NewRealClock())
+}
+
+func newRetryBudgetWithClock(ratio, minPerSecond float64, window time.Duration, clock glock.Clock) *RetryBudget {
+	return &RetryBudget{
+		clock:        clock,
+		attempts:     newRollingWindow(window, retryBudgetBuckets),
+		ratio:        ratio,
+		minPerSecond: minPerSecond,
+	}
+}
+
+// deposit records a successful first attempt.
+func (b *RetryBudget) deposit() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.attempts.add(b.clock.Now(), budgetDeposits)
+}
+
+// withdraw records a retry and returns true if the budget allows it. If the
+// budget is exhausted, no retry is recorded and false is returned.
+func (b *RetryBudget) withdraw() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	var (
+		now               = b.clock.Now()
+		deposits, retries = b.attempts.sums(now)
+	)
+
+	allowed := b.ratio*float64(deposits) + b.minPerSecond*b.attempts.window.Seconds()
+	if float64(retries+1) > allowed {
+		return false
+	}
+
+	b.attempts.add(now, budgetRetries)
+	return true
+}
@@ -0,0 +1,72 @@
+package overcurrent
+
+import (
+	"time"
+
+	"github.com/aphistic/sweet"
+	"github.com/efritz/glock"
+	. "github.com/onsi/gomega"
+)
+
+type BudgetSuite struct{}
+
+func (s *BudgetSuite) TestRatio(t sweet.T) {
+	budget := NewRetryBudget(0.2, 0, 10*time.Second)
+	Expect(budget.withdraw()).To(BeFalse())
+
+	times(10, budget.deposit)
+	Expect(budget.withdraw()).To(BeTrue())
+	Expect(budget.withdraw()).To(BeTrue())
+	Expect(budget.withdraw()).To(BeFalse())
+
+	times(5, budget.deposit)
+	Expect(budget.withdraw()).To(BeTrue())
+	Expect(budget.withdraw()).To(BeFalse())
+}
+
+func (s *BudgetSuite) TestMinimumPerSecond(t sweet.T) {
+	budget := NewRetryBudget(0, 0.5, 10*time.Second)
+
+	for i := 0; i < 5; i++ {
+		Expect(budget.withdraw()).To(BeTrue())
+	}
+
+	Expect(budget.withdraw()).To(BeFalse())
+}
+
+func (s *BudgetSuite) TestWindow(t sweet.T) {
+	var (
+		clock  = glock.NewMockClock()
+		budget = newRetryBudgetWithClock(0.5, 0, 10*time.Second, clock)
+	)
+
+	// Align the clock with the start of a bucket
+	clock.Advance(clock.Now().Truncate(time.Second).Add(time.Second).Sub(clock.Now()))
+
+	times(2, budget.deposit)
+	Expect(budget.withdraw()).To(BeTrue())
+	Expect(budget.withdraw()).To(BeFalse())
+
+	clock.Advance(5 * time.Second)
+	times(2, budget.deposit)
+	Expect(budget.withdraw()).To(BeTrue())
+	Expect(budget.withdraw()).To(BeFalse())
+
+	// Expire the first deposits and retry
+	clock.Advance(5 * time.Second)
+	Expect(budget.withdraw()).To(BeFalse())
+	times(2, budget.deposit)
+	Expect(budget.withdraw()).To(BeTrue())
+
+	// Expire everything
+	clock.Advance(10 * time.Second)
+	Expect(budget.withdraw()).To(BeFalse())
+}
+
+func (s *BudgetSuite) TestZeroWindow(t sweet.T) {
+	budget := NewRetryBudget(1, 0, 0)
+
+	// Deposits fall out of a clamped window immediately
+	Expect(budget.deposit).NotTo(Panic())
+	Expect(budget.withdraw()).To(BeFalse())
+}
@@ -0,0 +1,79 @@
+package overcurrent
+
+import (
+	"sync"
+	"time"
+)
+
+type testCollector struct {
+	configs     []BreakerConfig
+	counts      map[EventType]int
+	durationMap map[EventType][]time.Duration
+	gaugeMap    map[EventType][]float64
+	states      []CircuitState
+	mutex       sync.Mutex
+}
+
+func newTestCollector() *testCollector {
+	return &testCollector{
+		counts:      map[EventType]int{},
+		durationMap: map[EventType][]time.Duration{},
+		gaugeMap:    map[EventType][]float64{},
+	}
+}
+
+func (c *testCollector) ReportNew(config BreakerConfig) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.configs = append(c.configs, config)
+}
+
+func (c *testCollector) ReportCount(eventType EventType) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.counts[eventType]++
+}
+
+func (c *testCollector) ReportDuration(eventType EventType, duration time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.durationMap[eventType] = append(c.durationMap[eventType], duration)
+}
+
+func (c *testCollector) ReportState(state CircuitState) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.states = append(c.states, state)
+}
+
+func (c *testCollector) ReportGauge(eventType EventType, value float64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.gaugeMap[eventType] = append(c.gaugeMap[eventType], value)
+}
+
+func (c *testCollector) count(eventType EventType) int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.counts[eventType]
+}
+
+func (c *testCollector) durations(eventType EventType) []time.Duration {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return append([]time.Duration{}, c.durationMap[eventType]...)
+}
+
+func (c *testCollector) gauges(eventType EventType) []float64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return append([]float64{}, c.gaugeMap[eventType]...)
+}
@@ -0,0 +1,106 @@
+package overcurrent
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/efritz/glock"
+)
+
+// deadlineContext is a context whose deadline is driven by a glock.Clock
+// instead of the wall clock. It is canceled with context.DeadlineExceeded
+// once the clock advances past the timeout, or with the parent's error if
+// the parent context is canceled first.
+type deadlineContext struct {
+	context.Context
+	deadline time.Time
+	done     chan struct{}
+	once     sync.Once
+	mutex    sync.Mutex
+	err      error
+}
+
+// withClockTimeout creates a context derived from the parent context which
+// will be canceled after the given timeout elapses on the given clock. The
+// deadline of the resulting context is the minimum of the parent's deadline
+// and the clock's current time plus the timeout.
+func withClockTimeout(parent context.Context, clock glock.Clock, timeout time.Duration) (*deadlineContext, context.CancelFunc) {
+	deadline := clock.Now().Add(timeout)
+	if parentDeadline, ok := parent.Deadline(); ok && parentDeadline.Before(deadline) {
+		deadline = parentDeadline
+	}
+
+	ctx := &deadlineContext{
+		Context:  parent,
+		deadline: deadline,
+		done:     make(chan struct{}),
+	}
+
+	if err := parent.Err(); err != nil {
+		// Do not let the function observe a live context
+		// when the parent has already been canceled.
+		ctx.cancel(err)
+		return ctx, func() {}
+	}
+
+	// Register the timer before returning so that the timeout
+	// is measured from the time the context is created.
+	timer := clock.After(timeout)
+
+	go func() {
+		select {
+		case <-parent.Done():
+			ctx.cancel(parent.Err())
+
+		case <-timer:
+			ctx.cancel(context.DeadlineExceeded)
+
+		case <-ctx.done:
+		}
+	}()
+
+	return ctx, func() { ctx.cancel(context.Canceled) }
+}
+
+func (c *deadlineContext) Deadline() (time.Time, bool) {
+	return c.deadline, true
+}
+
+func (c *deadlineContext) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *deadlineContext) Err() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.err
+}
+
+func (c *deadlineContext) cancel(err error) {
+	c.once.Do(func() {
+		c.mutex.Lock()
+		c.err = err
+		c.mutex.Unlock()
+
+		close(c.done)
+	})
+}
+
+// detachedContext carries the values of its parent context, but not its
+// deadline or cancellation.
+type detachedContext struct {
+	parent context.Context
+}
+
+// withoutCancel creates a context with the values of the parent context which
+// is never canceled.
+func withoutCancel(parent context.Context) context.Context {
+	return detachedContext{parent: parent}
+}
+
+func (c detachedContext) Deadline() (time.Time, bool)       { return time.Time{}, false }
+func (c detachedContext) Done() <-chan struct{}             { return nil }
+func (c detachedContext) Err() error                        { return nil }
+func (c detachedContext) Value(key interface{}) interface{} { return c.parent.Value(key) }
@@ -0,0 +1,112 @@
+package overcurrent
+
+import (
+	"context"
+	"time"
+
+	"github.com/aphistic/sweet"
+	"github.com/efritz/glock"
+	. "github.com/onsi/gomega"
+)
+
+type ContextSuite struct{}
+
+func (s *ContextSuite) TestDeadline(t sweet.T) {
+	var (
+		clock       = glock.NewMockClock()
+		ctx, cancel = withClockTimeout(context.Background(), clock, time.Minute)
+	)
+
+	defer cancel()
+
+	deadline, ok := ctx.Deadline()
+	Expect(ok).To(BeTrue())
+	Expect(deadline).To(Equal(clock.Now().Add(time.Minute)))
+}
+
+func (s *ContextSuite) TestParentDeadlineSooner(t sweet.T) {
+	var (
+		clock                = glock.NewMockClock()
+		expected             = clock.Now().Add(time.Second)
+		parent, cancelParent = context.WithDeadline(context.Background(), expected)
+		ctx, cancel          = withClockTimeout(parent, clock, time.Minute)
+	)
+
+	defer cancelParent()
+	defer cancel()
+
+	deadline, ok := ctx.Deadline()
+	Expect(ok).To(BeTrue())
+	Expect(deadline).To(Equal(expected))
+}
+
+func (s *ContextSuite) TestTimeout(t sweet.T) {
+	var (
+		clock       = glock.NewMockClock()
+		ctx, cancel = withClockTimeout(context.Background(), clock, time.Minute)
+	)
+
+	defer cancel()
+
+	Consistently(ctx.Done()).ShouldNot(BeClosed())
+	Expect(ctx.Err()).To(BeNil())
+	clock.Advance(time.Minute)
+	Eventually(ctx.Done()).Should(BeClosed())
+	Expect(ctx.Err()).To(Equal(context.DeadlineExceeded))
+}
+
+func (s *ContextSuite) TestParentAlreadyCanceled(t sweet.T) {
+	var (
+		clock                = glock.NewMockClock()
+		parent, cancelParent = context.WithCancel(context.Background())
+	)
+
+	cancelParent()
+	ctx, cancel := withClockTimeout(parent, clock, time.Minute)
+	defer cancel()
+
+	Expect(ctx.Done()).To(BeClosed())
+	Expect(ctx.Err()).To(Equal(context.Canceled))
+}
+
+func (s *ContextSuite) TestParentCancel(t sweet.T) {
+	var (
+		clock                = glock.NewMockClock()
+		parent, cancelParent = context.WithCancel(context.Background())
+		ctx, cancel          = withClockTimeout(parent, clock, time.Minute)
+	)
+
+	defer cancel()
+
+	cancelParent()
+	Eventually(ctx.Done()).Should(BeClosed())
+	Expect(ctx.Err()).To(Equal(context.Canceled))
+}
+
+func (s *ContextSuite) TestCancel(t sweet.T) {
+	var (
+		clock       = glock.NewMockClock()
+		ctx, cancel = withClockTimeout(context.Background(), clock, time.Minute)
+	)
+
+	cancel()
+	Eventually(ctx.Done()).Should(BeClosed())
+	Expect(ctx.Err()).To(Equal(context.Canceled))
+}
+
+func (s *ContextSuite) TestWithoutCancel(t sweet.T) {
+	type key struct{}
+
+	var (
+		parent, cancel = context.WithTimeout(context.WithValue(context.Background(), key{}, "value"), time.Minute)
+		ctx            = withoutCancel(parent)
+	)
+
+	cancel()
+
+	_, ok := ctx.Deadline()
+	Expect(ok).To(BeFalse())
+	Expect(ctx.Done()).To(BeNil())
+	Expect(ctx.Err()).To(BeNil())
+	Expect(ctx.Value(key{})).To(Equal("value"))
+}
@@ -0,0 +1,144 @@
+package overcurrent
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+type (
+	// CircuitOpenError occurs when a breaker rejects a call because it is not
+	// closed. It matches ErrCircuitOpen via errors.Is.
+	CircuitOpenError struct {
+		// Name is the name of the breaker.
+		Name string
+
+		// State is the state of the breaker when the call was rejected.
+		State CircuitState
+
+		// RetryAfter is the time until the breaker will next attempt a call. It is
+		// zero when unknown, such as when the breaker is hard-open or half-closed.
+		RetryAfter time.Duration
+	}
+
+	// TimeoutError occurs when a breaker function does not complete within the
+	// invocation timeout. It matches ErrInvocationTimeout (and therefore also
+	// context.DeadlineExceeded) via errors.Is.
+	TimeoutError struct {
+		// Name is the name of the breaker.
+		Name string
+
+		// Timeout is the invocation timeout which was exceeded.
+		Timeout time.Duration
+	}
+
+	// RetryAfterError is an optional interface for errors returned by a breaker
+	// function which carry a hint from the dependency about how long to back off
+	// (e.g. an HTTP Retry-After header). If such an error trips the breaker, the
+	// breaker stays open for the given duration instead of the reset backoff.
+	RetryAfterError interface {
+		error
+		RetryAfter() time.Duration
+	}
+
+	// RetryBudgetError occurs when a failed call is not retried because the retry
+	// budget of the breaker is exhausted. It matches ErrRetryBudgetExhausted via
+	// errors.Is, and unwraps to the error of the last attempt.
+	RetryBudgetError struct {
+		// Err is the error of the last attempt.
+		Err error
+	}
+
+	// MaxConcurrencyError occurs when a registry rejects a call because the
+	// breaker is already running its maximum number of concurrent calls. It
+	// matches ErrMaxConcurrency via errors.Is.
+	MaxConcurrencyError struct {
+		// Name is the name of the breaker.
+		Name string
+
+		// MaxConcurrency is the concurrency limit of the breaker.
+		MaxConcurrency int
+	}
+
+	// RateLimitError occurs when a registry rejects a call because the breaker
+	// is over its rate limit. It matches ErrRateLimited via errors.Is.
+	RateLimitError struct {
+		// Name is the name of the breaker.
+		Name string
+
+		// Rate is the number of calls per second allowed by the breaker.
+		Rate float64
+	}
+)
+
+func (e *CircuitOpenError) Error() string {
+	message := withBreakerName(ErrCircuitOpen.Error(), e.Name)
+	if e.RetryAfter > 0 {
+		message = fmt.Sprintf("%s, retry after %s", message, e.RetryAfter)
+	}
+
+	return message
+}
+
+func (e *CircuitOpenError) Unwrap() error {
+	return ErrCircuitOpen
+}
+
+func (e *TimeoutError) Error() string {
+	return withBreakerName(fmt.Sprintf("invocation has timed out after %s", e.Timeout), e.Name)
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return ErrInvocationTimeout
+}
+
+func (e *RetryBudgetError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrRetryBudgetExhausted.Error(), e.Err)
+}
+
+func (e *RetryBudgetError) Is(target error) bool {
+	return target == ErrRetryBudgetExhausted
+}
+
+func (e *RetryBudgetError) Unwrap() error {
+	return e.Err
+}
+
+func (e *MaxConcurrencyError) Error() string {
+	return withBreakerName(fmt.Sprintf("%s of %d", ErrMaxConcurrency.Error(), e.MaxConcurrency), e.Name)
+}
+
+func (e *MaxConcurrencyError) Unwrap() error {
+	return ErrMaxConcurrency
+}
+
+func (e *RateLimitError) Error() string {
+	return withBreakerName(fmt.Sprintf("%s to %g calls per second", ErrRateLimited.Error(), e.Rate), e.Name)
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return ErrRateLimited
+}
+
+// retryAfter returns the positive open period requested by the first error in
+// the chain which implements RetryAfterError, if any.
+func retryAfter(err error) *time.Duration {
+	var retryErr RetryAfterError
+	if !errors.As(err, &retryErr) {
+		return nil
+	}
+
+	if duration := retryErr.RetryAfter(); duration > 0 {
+		return &duration
+	}
+
+	return nil
+}
+
+func withBreakerName(message, name string) string {
+	if name == "" {
+		return message
+	}
+
+	return fmt.Sprintf("%s (breaker %q)", message, name)
+}
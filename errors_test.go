@@ -0,0 +1,43 @@
+package overcurrent
+
+import (
+	"errors"
+	"time"
+
+	"github.com/aphistic/sweet"
+	. "github.com/onsi/gomega"
+)
+
+type ErrorsSuite struct{}
+
+func (s *ErrorsSuite) TestCircuitOpenError(t sweet.T) {
+	err := error(&CircuitOpenError{Name: "test", State: StateOpen, RetryAfter: 5 * time.Second})
+	Expect(errors.Is(err, ErrCircuitOpen)).To(BeTrue())
+	Expect(err.Error()).To(Equal(`circuit is open (breaker "test"), retry after 5s`))
+	Expect((&CircuitOpenError{State: StateHardOpen}).Error()).To(Equal("circuit is open"))
+}
+
+func (s *ErrorsSuite) TestTimeoutError(t sweet.T) {
+	err := error(&TimeoutError{Name: "test", Timeout: time.Second})
+	Expect(errors.Is(err, ErrInvocationTimeout)).To(BeTrue())
+	Expect(err.Error()).To(Equal(`invocation has timed out after 1s (breaker "test")`))
+}
+
+func (s *ErrorsSuite) TestMaxConcurrencyError(t sweet.T) {
+	err := error(&MaxConcurrencyError{Name: "test", MaxConcurrency: 10})
+	Expect(errors.Is(err, ErrMaxConcurrency)).To(BeTrue())
+	Expect(err.Error()).To(Equal(`breaker is at max concurrency of 10 (breaker "test")`))
+}
+
+func (s *ErrorsSuite) TestRateLimitError(t sweet.T) {
+	err := error(&RateLimitError{Name: "test", Rate: 2.5})
+	Expect(errors.Is(err, ErrRateLimited)).To(BeTrue())
+	Expect(err.Error()).To(Equal(`breaker is rate limited to 2.5 calls per second (breaker "test")`))
+}
+
+func (s *ErrorsSuite) TestRetryBudgetError(t sweet.T) {
+	err := error(&RetryBudgetError{Err: testErr})
+	Expect(errors.Is(err, ErrRetryBudgetExhausted)).To(BeTrue())
+	Expect(errors.Is(err, testErr)).To(BeTrue())
+	Expect(err.Error()).To(Equal("retry budget exhausted: test error"))
+}
@@ -0,0 +1,137 @@
+package overcurrent
+
+import "context"
+
+type (
+	// TypedBreakerFunc is a breaker function which produces a value.
+	TypedBreakerFunc[T any] func(ctx context.Context) (T, error)
+
+	// TypedFallbackFunc is a fallback function which produces a value.
+	TypedFallbackFunc[T any] func(error) (T, error)
+
+	// Future is the eventual result of a function invoked by ExecuteAsync or
+	// ExecuteRegistryAsync.
+	Future[T any] struct {
+		done  chan struct{}
+		value T
+		err   error
+	}
+)
+
+// Execute invokes the given function via the CallContext method of the given
+// breaker and returns the value produced by the function. If the function fails
+// or is not invoked (e.g. the circuit is open), the zero value is returned along
+// with the error.
+func Execute[T any](ctx context.Context, breaker CircuitBreaker, f TypedBreakerFunc[T]) (T, error) {
+	results := make(chan T, 1)
+	return receive(results, breaker.CallContext(ctx, capture(f, results)))
+}
+
+// ExecuteRegistry invokes the given function via the CallContext method of the
+// given registry and returns the value produced by the function. If the function
+// fails or is not invoked, the value produced by the fallback is returned instead.
+// The fallback may be nil.
+func ExecuteRegistry[T any](ctx context.Context, registry Registry, name string, f TypedBreakerFunc[T], fallback TypedFallbackFunc[T]) (T, error) {
+	var (
+		results         = make(chan T, 1)
+		fallbackResults = make(chan T, 1)
+		fallbackFunc    FallbackFunc
+	)
+
+	if fallback != nil {
+		fallbackFunc = func(err error) error {
+			value, err := fallback(err)
+			if err == nil {
+				fallbackResults <- value
+			}
+
+			return err
+		}
+	}
+
+	if err := registry.CallContext(ctx, name, capture(f, results), fallbackFunc); err != nil {
+		var zero T
+		return zero, err
+	}
+
+	select {
+	case value := <-fallbackResults:
+		return value, nil
+	default:
+	}
+
+	return receive(results, nil)
+}
+
+// ExecuteAsync invokes Execute in a goroutine and returns a future which will
+// receive the result.
+func ExecuteAsync[T any](ctx context.Context, breaker CircuitBreaker, f TypedBreakerFunc[T]) *Future[T] {
+	return newFuture(func() (T, error) {
+		return Execute(ctx, breaker, f)
+	})
+}
+
+// ExecuteRegistryAsync invokes ExecuteRegistry in a goroutine and returns a
+// future which will receive the result.
+func ExecuteRegistryAsync[T any](ctx context.Context, registry Registry, name string, f TypedBreakerFunc[T], fallback TypedFallbackFunc[T]) *Future[T] {
+	return newFuture(func() (T, error) {
+		return ExecuteRegistry(ctx, registry, name, f, fallback)
+	})
+}
+
+func newFuture[T any](f func() (T, error)) *Future[T] {
+	future := &Future[T]{
+		done: make(chan struct{}),
+	}
+
+	go func() {
+		defer close(future.done)
+		future.value, future.err = f()
+	}()
+
+	return future
+}
+
+// Done returns a channel which closes once the result is available.
+func (f *Future[T]) Done() <-chan struct{} {
+	return f.done
+}
+
+// Get blocks until the result is available and then returns it.
+func (f *Future[T]) Get() (T, error) {
+	<-f.done
+	return f.value, f.err
+}
+
+// capture converts a typed breaker function into a breaker function which
+// writes successful values into the given channel. The value is passed over
+// a channel rather than captured in a variable as the function may still be
+// running after the breaker has returned (e.g. after a timeout).
+func capture[T any](f TypedBreakerFunc[T], results chan<- T) BreakerFunc {
+	return func(ctx context.Context) error {
+		value, err := f(ctx)
+		if err == nil {
+			select {
+			case results <- value:
+			default:
+			}
+		}
+
+		return err
+	}
+}
+
+// receive returns the captured value if the invocation was successful.
+func receive[T any](results <-chan T, err error) (T, error) {
+	var zero T
+	if err != nil {
+		return zero, err
+	}
+
+	select {
+	case value := <-results:
+		return value, nil
+	default:
+		return zero, nil
+	}
+}
@@ -0,0 +1,150 @@
+package overcurrent
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aphistic/sweet"
+	"github.com/efritz/glock"
+	. "github.com/onsi/gomega"
+)
+
+type ExecuteSuite struct{}
+
+func (s *ExecuteSuite) TestExecute(t sweet.T) {
+	breaker := NewCircuitBreaker(testConfig())
+
+	value, err := Execute(context.Background(), breaker, func(ctx context.Context) (int, error) {
+		return 42, nil
+	})
+
+	Expect(err).To(BeNil())
+	Expect(value).To(Equal(42))
+}
+
+func (s *ExecuteSuite) TestExecuteError(t sweet.T) {
+	breaker := NewCircuitBreaker(testConfig())
+
+	value, err := Execute(context.Background(), breaker, func(ctx context.Context) (string, error) {
+		return "partial", testErr
+	})
+
+	Expect(err).To(Equal(testErr))
+	Expect(value).To(BeEmpty())
+}
+
+func (s *ExecuteSuite) TestExecuteTimeout(t sweet.T) {
+	var (
+		clock   = glock.NewMockClock()
+		breaker = NewCircuitBreaker(testConfig(), withClock(clock))
+		block   = make(chan struct{})
+	)
+
+	defer close(block)
+
+	go func() {
+		clock.BlockingAdvance(time.Minute)
+	}()
+
+	value, err := Execute(context.Background(), breaker, func(ctx context.Context) (int, error) {
+		<-block
+		return 42, nil
+	})
+
+	Expect(err).To(beError(ErrInvocationTimeout))
+	Expect(value).To(Equal(0))
+}
+
+func (s *ExecuteSuite) TestExecuteRegistry(t sweet.T) {
+	r := NewRegistry()
+	r.Configure("test", testConfig())
+
+	value, err := ExecuteRegistry(context.Background(), r, "test", func(ctx context.Context) (int, error) {
+		return 42, nil
+	}, func(err error) (int, error) {
+		return 24, nil
+	})
+
+	Expect(err).To(BeNil())
+	Expect(value).To(Equal(42))
+}
+
+func (s *ExecuteSuite) TestExecuteRegistryFallback(t sweet.T) {
+	r := NewRegistry()
+	r.Configure("test", testConfig())
+
+	value, err := ExecuteRegistry(context.Background(), r, "test", func(ctx context.Context) (int, error) {
+		return 42, testErr
+	}, func(err error) (int, error) {
+		Expect(err).To(Equal(testErr))
+		return 24, nil
+	})
+
+	Expect(err).To(BeNil())
+	Expect(value).To(Equal(24))
+}
+
+func (s *ExecuteSuite) TestExecuteRegistryFallbackError(t sweet.T) {
+	var (
+		r  = NewRegistry()
+		ex = errors.New("utoh")
+	)
+
+	r.Configure("test", testConfig())
+
+	value, err := ExecuteRegistry(context.Background(), r, "test", func(ctx context.Context) (int, error) {
+		return 42, testErr
+	}, func(err error) (int, error) {
+		return 24, ex
+	})
+
+	Expect(err).To(Equal(ex))
+	Expect(value).To(Equal(0))
+}
+
+func (s *ExecuteSuite) TestExecuteRegistryNoFallback(t sweet.T) {
+	r := NewRegistry()
+	r.Configure("test", testConfig())
+
+	_, err := ExecuteRegistry(context.Background(), r, "test", func(ctx context.Context) (int, error) {
+		return 42, testErr
+	}, nil)
+
+	Expect(err).To(Equal(testErr))
+}
+
+func (s *ExecuteSuite) TestExecuteAsync(t sweet.T) {
+	var (
+		breaker = NewCircuitBreaker(testConfig())
+		block   = make(chan struct{})
+	)
+
+	future := ExecuteAsync(context.Background(), breaker, func(ctx context.Context) (int, error) {
+		<-block
+		return 42, nil
+	})
+
+	Consistently(future.Done()).ShouldNot(BeClosed())
+	close(block)
+	Eventually(future.Done()).Should(BeClosed())
+
+	value, err := future.Get()
+	Expect(err).To(BeNil())
+	Expect(value).To(Equal(42))
+}
+
+func (s *ExecuteSuite) TestExecuteRegistryAsync(t sweet.T) {
+	r := NewRegistry()
+	r.Configure("test", testConfig())
+
+	future := ExecuteRegistryAsync(context.Background(), r, "test", func(ctx context.Context) (int, error) {
+		return 42, testErr
+	}, func(err error) (int, error) {
+		return 24, nil
+	})
+
+	value, err := future.Get()
+	Expect(err).To(BeNil())
+	Expect(value).To(Equal(24))
+}
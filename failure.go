@@ -1,5 +1,11 @@
 package overcurrent
 
+import (
+	"errors"
+	"net"
+	"reflect"
+)
+
 type (
 	// FailureInterpreter is the interface that determines if an error should
 	// affect whether or not the circuit breaker will trip. This is useful if
@@ -25,3 +31,134 @@ func NewAnyErrorFailureInterpreter() FailureInterpreter {
 		return true
 	})
 }
+
+// NewErrorIsFailureInterpreter creates a failure interpreter that trips on errors
+// which match one of the given errors via errors.Is.
+func NewErrorIsFailureInterpreter(targets ...error) FailureInterpreter {
+	return FailureInterpreterFunc(func(err error) bool {
+		return isAny(err, targets)
+	})
+}
+
+// NewErrorAsFailureInterpreter creates a failure interpreter that trips on errors
+// which match the type of one of the given targets via errors.As. As with errors.As,
+// each target must be a non-nil pointer to a type implementing error or to any
+// interface type (e.g. new(*os.PathError) or new(net.Error)). The targets are used
+// only for their types and are never written to.
+func NewErrorAsFailureInterpreter(targets ...interface{}) FailureInterpreter {
+	types := make([]reflect.Type, 0, len(targets))
+	for _, target := range targets {
+		value := reflect.ValueOf(target)
+		if value.Kind() != reflect.Ptr || value.IsNil() {
+			panic("overcurrent: error-as target must be a non-nil pointer")
+		}
+
+		types = append(types, value.Type().Elem())
+	}
+
+	return FailureInterpreterFunc(func(err error) bool {
+		for _, typ := range types {
+			// Use a fresh target for each call as interpreters may be
+			// invoked concurrently.
+			if errors.As(err, reflect.New(typ).Interface()) {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+// NewIgnoreErrorsFailureInterpreter creates a failure interpreter that trips on
+// every error except those which match one of the given errors via errors.Is.
+func NewIgnoreErrorsFailureInterpreter(ignored ...error) FailureInterpreter {
+	return FailureInterpreterFunc(func(err error) bool {
+		return !isAny(err, ignored)
+	})
+}
+
+// NewNetErrorFailureInterpreter creates a failure interpreter that trips only on
+// errors which wrap a net.Error that is a timeout or is temporary.
+func NewNetErrorFailureInterpreter() FailureInterpreter {
+	return FailureInterpreterFunc(func(err error) bool {
+		var netErr net.Error
+		if !errors.As(err, &netErr) {
+			return false
+		}
+
+		return netErr.Timeout() || netErr.Temporary()
+	})
+}
+
+// NewHTTPStatusFailureInterpreter creates a failure interpreter that classifies
+// errors by HTTP status code. The status code is read from the first error in the
+// chain which has a `StatusCode() int` method. The interpreter trips if the status
+// code is one of the given codes or, if no codes are given, if the status code is
+// at least 500. Errors without a status code (e.g. connection failures) trip.
+func NewHTTPStatusFailureInterpreter(codes ...int) FailureInterpreter {
+	return FailureInterpreterFunc(func(err error) bool {
+		var statusErr interface{ StatusCode() int }
+		if !errors.As(err, &statusErr) {
+			return true
+		}
+
+		status := statusErr.StatusCode()
+		if len(codes) == 0 {
+			return status >= 500
+		}
+
+		for _, code := range codes {
+			if status == code {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+// NewOrFailureInterpreter creates a failure interpreter that trips if any of the
+// given interpreters trip.
+func NewOrFailureInterpreter(interpreters ...FailureInterpreter) FailureInterpreter {
+	return FailureInterpreterFunc(func(err error) bool {
+		for _, interpreter := range interpreters {
+			if interpreter.ShouldTrip(err) {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+// NewAndFailureInterpreter creates a failure interpreter that trips only if all of
+// the given interpreters trip.
+func NewAndFailureInterpreter(interpreters ...FailureInterpreter) FailureInterpreter {
+	return FailureInterpreterFunc(func(err error) bool {
+		for _, interpreter := range interpreters {
+			if !interpreter.ShouldTrip(err) {
+				return false
+			}
+		}
+
+		return len(interpreters) > 0
+	})
+}
+
+// NewNotFailureInterpreter creates a failure interpreter that trips only if the
+// given interpreter does not.
+func NewNotFailureInterpreter(interpreter FailureInterpreter) FailureInterpreter {
+	return FailureInterpreterFunc(func(err error) bool {
+		return !interpreter.ShouldTrip(err)
+	})
+}
+
+func isAny(err error, targets []error) bool {
+	for _, target := range targets {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
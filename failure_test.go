@@ -1,7 +1,11 @@
 package overcurrent
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"net"
+	"os"
 
 	"github.com/aphistic/sweet"
 	. "github.com/onsi/gomega"
@@ -18,3 +22,102 @@ func (s *FailureSuite) TestAnyError(t sweet.T) {
 	Expect(afi.ShouldTrip(nil)).To(BeTrue())
 	Expect(afi.ShouldTrip(err)).To(BeTrue())
 }
+
+func (s *FailureSuite) TestErrorIs(t sweet.T) {
+	fi := NewErrorIsFailureInterpreter(context.DeadlineExceeded, testErr)
+
+	Expect(fi.ShouldTrip(testErr)).To(BeTrue())
+	Expect(fi.ShouldTrip(fmt.Errorf("wrapped: %w", testErr))).To(BeTrue())
+	Expect(fi.ShouldTrip(ErrInvocationTimeout)).To(BeTrue())
+	Expect(fi.ShouldTrip(errors.New("other"))).To(BeFalse())
+}
+
+func (s *FailureSuite) TestErrorAs(t sweet.T) {
+	fi := NewErrorAsFailureInterpreter(new(*os.PathError), new(*testStatusError))
+
+	Expect(fi.ShouldTrip(fmt.Errorf("wrapped: %w", &os.PathError{Op: "open", Err: os.ErrNotExist}))).To(BeTrue())
+	Expect(fi.ShouldTrip(&testStatusError{status: 404})).To(BeTrue())
+	Expect(fi.ShouldTrip(testErr)).To(BeFalse())
+}
+
+func (s *FailureSuite) TestErrorAsInvalidTarget(t sweet.T) {
+	Expect(func() { NewErrorAsFailureInterpreter(os.PathError{}) }).To(Panic())
+	Expect(func() { NewErrorAsFailureInterpreter((*error)(nil)) }).To(Panic())
+}
+
+func (s *FailureSuite) TestIgnoreErrors(t sweet.T) {
+	fi := NewIgnoreErrorsFailureInterpreter(os.ErrNotExist)
+
+	Expect(fi.ShouldTrip(fmt.Errorf("wrapped: %w", os.ErrNotExist))).To(BeFalse())
+	Expect(fi.ShouldTrip(testErr)).To(BeTrue())
+}
+
+func (s *FailureSuite) TestNetError(t sweet.T) {
+	fi := NewNetErrorFailureInterpreter()
+
+	Expect(fi.ShouldTrip(&net.DNSError{IsTimeout: true})).To(BeTrue())
+	Expect(fi.ShouldTrip(&net.DNSError{IsTemporary: true})).To(BeTrue())
+	Expect(fi.ShouldTrip(fmt.Errorf("wrapped: %w", &net.DNSError{IsTimeout: true}))).To(BeTrue())
+	Expect(fi.ShouldTrip(&net.DNSError{IsNotFound: true})).To(BeFalse())
+	Expect(fi.ShouldTrip(testErr)).To(BeFalse())
+}
+
+func (s *FailureSuite) TestHTTPStatus(t sweet.T) {
+	fi := NewHTTPStatusFailureInterpreter()
+
+	Expect(fi.ShouldTrip(&testStatusError{status: 500})).To(BeTrue())
+	Expect(fi.ShouldTrip(fmt.Errorf("wrapped: %w", &testStatusError{status: 503}))).To(BeTrue())
+	Expect(fi.ShouldTrip(&testStatusError{status: 404})).To(BeFalse())
+	Expect(fi.ShouldTrip(testErr)).To(BeTrue())
+}
+
+func (s *FailureSuite) TestHTTPStatusCodes(t sweet.T) {
+	fi := NewHTTPStatusFailureInterpreter(429, 503)
+
+	Expect(fi.ShouldTrip(&testStatusError{status: 429})).To(BeTrue())
+	Expect(fi.ShouldTrip(&testStatusError{status: 503})).To(BeTrue())
+	Expect(fi.ShouldTrip(&testStatusError{status: 500})).To(BeFalse())
+}
+
+func (s *FailureSuite) TestOr(t sweet.T) {
+	fi := NewOrFailureInterpreter(
+		NewErrorIsFailureInterpreter(os.ErrNotExist),
+		NewErrorIsFailureInterpreter(os.ErrExist),
+	)
+
+	Expect(fi.ShouldTrip(os.ErrNotExist)).To(BeTrue())
+	Expect(fi.ShouldTrip(os.ErrExist)).To(BeTrue())
+	Expect(fi.ShouldTrip(testErr)).To(BeFalse())
+	Expect(NewOrFailureInterpreter().ShouldTrip(testErr)).To(BeFalse())
+}
+
+func (s *FailureSuite) TestAnd(t sweet.T) {
+	fi := NewAndFailureInterpreter(
+		NewHTTPStatusFailureInterpreter(),
+		NewIgnoreErrorsFailureInterpreter(testErr),
+	)
+
+	Expect(fi.ShouldTrip(&testStatusError{status: 500})).To(BeTrue())
+	Expect(fi.ShouldTrip(&testStatusError{status: 400})).To(BeFalse())
+	Expect(fi.ShouldTrip(testErr)).To(BeFalse())
+	Expect(NewAndFailureInterpreter().ShouldTrip(testErr)).To(BeFalse())
+}
+
+func (s *FailureSuite) TestNot(t sweet.T) {
+	fi := NewNotFailureInterpreter(NewErrorIsFailureInterpreter(os.ErrNotExist))
+
+	Expect(fi.ShouldTrip(os.ErrNotExist)).To(BeFalse())
+	Expect(fi.ShouldTrip(testErr)).To(BeTrue())
+}
+
+type testStatusError struct {
+	status int
+}
+
+func (e *testStatusError) Error() string {
+	return fmt.Sprintf("status %d", e.status)
+}
+
+func (e *testStatusError) StatusCode() int {
+	return e.status
+}
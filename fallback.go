@@ -0,0 +1,96 @@
+package overcurrent
+
+import (
+	"context"
+	"errors"
+)
+
+type (
+	// FallbackContextFunc is a fallback function which also receives the context
+	// given to the registry, the name of the breaker, and the reason the fallback
+	// was invoked. The context carries the values of the caller's context, but not
+	// its deadline or cancellation; the fallback is bounded only by the breaker's
+	// fallback timeout.
+	FallbackContextFunc func(ctx context.Context, name string, reason FallbackReason, err error) error
+
+	// FallbackReason distinguishes the causes of a fallback.
+	FallbackReason int
+)
+
+const (
+	_ FallbackReason = iota
+
+	// FallbackReasonCircuitOpen occurs when the breaker did not invoke the
+	// breaker func because the circuit is open (or the call was throttled).
+	FallbackReasonCircuitOpen
+
+	// FallbackReasonTimeout occurs when the breaker func did not complete
+	// within the invocation timeout.
+	FallbackReasonTimeout
+
+	// FallbackReasonRejection occurs when the breaker func was not invoked
+	// due to max concurrency, the rate limit, or too many abandoned calls.
+	FallbackReasonRejection
+
+	// FallbackReasonError occurs when the breaker func returned an error.
+	FallbackReasonError
+)
+
+func (r FallbackReason) String() string {
+	switch r {
+	case FallbackReasonCircuitOpen:
+		return "circuit open"
+	case FallbackReasonTimeout:
+		return "timeout"
+	case FallbackReasonRejection:
+		return "rejection"
+	case FallbackReasonError:
+		return "error"
+	}
+
+	return "unknown"
+}
+
+// FallbackChain creates a fallback function which invokes each of the given
+// fallback functions in order until one of them succeeds. Each fallback is
+// given the error of the breaker func, not the error of the previous fallback.
+// The error of the last fallback is returned if none succeed (or the error of
+// the breaker func if no fallbacks are given).
+func FallbackChain(fallbacks ...FallbackContextFunc) FallbackContextFunc {
+	return func(ctx context.Context, name string, reason FallbackReason, err error) error {
+		fallbackErr := err
+		for _, fallback := range fallbacks {
+			if fallbackErr = fallback(ctx, name, reason, err); fallbackErr == nil {
+				return nil
+			}
+		}
+
+		return fallbackErr
+	}
+}
+
+// withContext adapts the fallback function to a FallbackContextFunc. A nil
+// fallback function remains nil.
+func (f FallbackFunc) withContext() FallbackContextFunc {
+	if f == nil {
+		return nil
+	}
+
+	return func(ctx context.Context, name string, reason FallbackReason, err error) error {
+		return f(err)
+	}
+}
+
+// fallbackReason determines why the given error caused a fallback.
+func fallbackReason(err error) FallbackReason {
+	switch {
+	case errors.Is(err, ErrCircuitOpen):
+		return FallbackReasonCircuitOpen
+	case errors.Is(err, ErrInvocationTimeout):
+		return FallbackReasonTimeout
+	case errors.Is(err, ErrMaxConcurrency), errors.Is(err, ErrRateLimited), errors.Is(err, ErrMaxAbandoned):
+		return FallbackReasonRejection
+	}
+
+	return FallbackReasonError
+}
@@ -0,0 +1,51 @@
+package overcurrent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aphistic/sweet"
+	. "github.com/onsi/gomega"
+)
+
+type FallbackSuite struct{}
+
+func (s *FallbackSuite) TestFallbackReason(t sweet.T) {
+	Expect(fallbackReason(&CircuitOpenError{})).To(Equal(FallbackReasonCircuitOpen))
+	Expect(fallbackReason(&TimeoutError{})).To(Equal(FallbackReasonTimeout))
+	Expect(fallbackReason(&RetryBudgetError{Err: &TimeoutError{}})).To(Equal(FallbackReasonTimeout))
+	Expect(fallbackReason(&MaxConcurrencyError{})).To(Equal(FallbackReasonRejection))
+	Expect(fallbackReason(&RateLimitError{})).To(Equal(FallbackReasonRejection))
+	Expect(fallbackReason(ErrMaxAbandoned)).To(Equal(FallbackReasonRejection))
+	Expect(fallbackReason(testErr)).To(Equal(FallbackReasonError))
+	Expect(FallbackReasonTimeout.String()).To(Equal("timeout"))
+}
+
+func (s *FallbackSuite) TestFallbackChain(t sweet.T) {
+	var (
+		calls = []string{}
+		errA  = fmt.Errorf("a")
+		errB  = fmt.Errorf("b")
+	)
+
+	fallback := func(name string, err error) FallbackContextFunc {
+		return func(ctx context.Context, breakerName string, reason FallbackReason, cause error) error {
+			Expect(breakerName).To(Equal("test"))
+			Expect(reason).To(Equal(FallbackReasonError))
+			Expect(cause).To(Equal(testErr))
+			calls = append(calls, name)
+			return err
+		}
+	}
+
+	chain := FallbackChain(fallback("a", errA), fallback("b", nil), fallback("c", nil))
+	Expect(chain(context.Background(), "test", FallbackReasonError, testErr)).To(BeNil())
+	Expect(calls).To(Equal([]string{"a", "b"}))
+
+	calls = calls[:0]
+	chain = FallbackChain(fallback("a", errA), fallback("b", errB))
+	Expect(chain(context.Background(), "test", FallbackReasonError, testErr)).To(Equal(errB))
+	Expect(calls).To(Equal([]string{"a", "b"}))
+
+	Expect(FallbackChain()(context.Background(), "test", FallbackReasonError, testErr)).To(Equal(testErr))
+}
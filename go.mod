@@ -1,11 +1,25 @@
 module github.com/efritz/overcurrent
 
+go 1.18
+
 require (
 	github.com/aphistic/sweet v0.0.0-20180618201346-68e18ab55a67
 	github.com/aphistic/sweet-junit v0.0.0-20171005212431-6b78f7014f7c
 	github.com/efritz/backoff v0.0.0-20181228195520-96f666d52d44
 	github.com/efritz/glock v0.0.0-20180604185841-7e95e8b27a61
-	github.com/efritz/response v0.0.0-20180829153605-6e034bf5a1db // indirect
 	github.com/efritz/sse v0.0.0-20181115162819-b93a5a07589b
 	github.com/onsi/gomega v1.4.3
 )
+
+require (
+	github.com/efritz/response v0.0.0-20180829153605-6e034bf5a1db // indirect
+	github.com/kr/pretty v0.3.1 // indirect
+	github.com/mattn/go-colorable v0.0.9 // indirect
+	github.com/mattn/go-isatty v0.0.4 // indirect
+	github.com/mgutz/ansi v0.0.0-20170206155736-9520e82c474b // indirect
+	golang.org/x/crypto v0.0.0-20181203042331-505ab145d0a9 // indirect
+	golang.org/x/net v0.0.0-20181220203305-927f97764cc3 // indirect
+	golang.org/x/sys v0.0.0-20181228144115-9a3f9b0469bb // indirect
+	golang.org/x/text v0.3.0 // indirect
+	gopkg.in/yaml.v2 v2.2.2 // indirect
+)
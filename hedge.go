@@ -0,0 +1,110 @@
+package overcurrent
+
+import (
+	"context"
+	"time"
+)
+
+type (
+	// hedgePolicy determines when a registry starts a second attempt of a call
+	// which has not yet completed.
+	hedgePolicy struct {
+		delay    time.Duration
+		adaptive *adaptiveTimeout
+		budget   *RetryBudget
+	}
+
+	hedgeResult struct {
+		err   error
+		hedge bool
+	}
+
+	hedgingAllowedKey struct{}
+)
+
+// AllowHedging marks calls made through a registry with the returned context
+// as idempotent. Only such calls are hedged by breakers configured with
+// WithHedging or WithAdaptiveHedging.
+func AllowHedging(ctx context.Context) context.Context {
+	return context.WithValue(ctx, hedgingAllowedKey{}, true)
+}
+
+// hedgingAllowed returns true if the given context was created by AllowHedging.
+func hedgingAllowed(ctx context.Context) bool {
+	allowed, _ := ctx.Value(hedgingAllowedKey{}).(bool)
+	return allowed
+}
+
+// currentDelay returns how long to wait for the first attempt before hedging.
+func (p *hedgePolicy) currentDelay() time.Duration {
+	if p.adaptive != nil {
+		return p.adaptive.current()
+	}
+
+	return p.delay
+}
+
+// callWithHedging invokes the given function via the breaker's semaphore. If the
+// breaker has a hedge policy, the call allows hedging, and the first attempt does
+// not complete before the hedge delay, a second attempt is started. The first attempt to succeed wins and
+// the other is canceled. If both attempts fail, the error of the first attempt is
+// returned.
+func (r *registry) callWithHedging(ctx context.Context, wrapped *wrappedBreaker, f BreakerFunc) error {
+	breaker := wrapped.breaker
+	if breaker.hedging == nil || !hedgingAllowed(ctx) {
+		return r.callWithSemaphore(ctx, breaker, wrapped.semaphore, f)
+	}
+
+	var (
+		results                   = make(chan hedgeResult, 2)
+		primaryCtx, cancelPrimary = context.WithCancel(ctx)
+	)
+
+	defer cancelPrimary()
+
+	attempt := func(ctx context.Context, hedge bool) {
+		results <- hedgeResult{r.callWithSemaphore(ctx, breaker, wrapped.semaphore, f), hedge}
+	}
+
+	go attempt(primaryCtx, false)
+
+	select {
+	case result := <-results:
+		if result.err == nil && breaker.hedging.budget != nil {
+			breaker.hedging.budget.deposit()
+		}
+
+		return result.err
+
+	case <-breaker.clock.After(breaker.hedging.currentDelay()):
+
+	case <-ctx.Done():
+		return (<-results).err
+	}
+
+	if breaker.hedging.budget != nil && !breaker.hedging.budget.withdraw() {
+		return (<-results).err
+	}
+
+	hedgeCtx, cancelHedge := context.WithCancel(ctx)
+	defer cancelHedge()
+
+	breaker.collector.ReportCount(EventTypeHedge)
+	go attempt(hedgeCtx, true)
+
+	first := <-results
+	if first.err == nil {
+		return nil
+	}
+
+	second := <-results
+	if second.err == nil {
+		return nil
+	}
+
+	if first.hedge {
+		return second.err
+	}
+
+	return first.err
+}
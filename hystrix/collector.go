@@ -54,7 +54,7 @@ func (c *Collector) Stop() {
 func (c *Collector) Handler() http.Handler {
 	server := sse.NewServer(c.events)
 	go server.Start()
-	return server
+	return server.ServeHTTP
 }
 
 func (c *Collector) ReportNew(name string, config overcurrent.BreakerConfig) {
@@ -75,6 +75,10 @@ func (c *Collector) ReportState(name string, state overcurrent.CircuitState) {
 	c.getStats(name).SetState(state)
 }
 
+func (c *Collector) ReportGauge(name string, eventType overcurrent.EventType, value float64) {
+	c.getStats(name).SetGauge(eventType, value)
+}
+
 func (c *Collector) getNames() []string {
 	c.mutex.RLock()
 	defer c.mutex.RUnlock()
@@ -111,12 +115,20 @@ func makeCommandStats(name string, stats *FrozenBreakerStats) map[string]interfa
 		numErrors       = stats.counters[overcurrent.EventTypeFailure]
 		numRequests     = stats.counters[overcurrent.EventTypeAttempt]
 		errorPercentage = 0.0
+		isolation       = "SEMAPHORE"
+		semRejected     = stats.counters[overcurrent.EventTypeRejection] + stats.counters[overcurrent.EventTypeRateLimited]
+		poolRejected    = 0
 	)
 
 	if numRequests > 0 {
 		errorPercentage = math.Min(1, (float64(numErrors)/float64(numRequests))) * 100
 	}
 
+	if stats.config.PoolCoreSize > 0 {
+		isolation = "THREAD"
+		semRejected, poolRejected = 0, semRejected
+	}
+
 	properties := map[string]interface{}{
 		"type":                                  "HystrixCommand",
 		"name":                                  name,
@@ -128,17 +140,26 @@ func makeCommandStats(name string, stats *FrozenBreakerStats) map[string]interfa
 		"rollingCountSuccess":                   stats.counters[overcurrent.EventTypeSuccess],
 		"rollingCountFailure":                   stats.counters[overcurrent.EventTypeError],
 		"rollingCountBadRequest":                stats.counters[overcurrent.EventTypeBadRequest],
+		"rollingCountExceptionsThrown":          stats.counters[overcurrent.EventTypePanic],
 		"rollingCountShortCircuited":            stats.counters[overcurrent.EventTypeShortCircuit],
 		"rollingCountTimeout":                   stats.counters[overcurrent.EventTypeTimeout],
-		"rollingCountSemaphoreRejected":         stats.counters[overcurrent.EventTypeRejection],
+		"rollingCountSemaphoreRejected":         semRejected,
+		"rollingCountThreadPoolRejected":        poolRejected,
 		"rollingCountFallbackSuccess":           stats.counters[overcurrent.EventTypeFallbackSuccess],
 		"rollingCountFallbackFailure":           stats.counters[overcurrent.EventTypeFallbackFailure],
+		"rollingCountFallbackRejection":         stats.counters[overcurrent.EventTypeFallbackRejection],
 		"latencyExecute":                        makeLatencies(runDurations),
 		"latencyTotal":                          makeLatencies(totalDurations),
 		"latencyExecute_mean":                   int(mean(runDurations) / time.Millisecond),
 		"latencyTotal_mean":                     int(mean(totalDurations) / time.Millisecond),
 		"isCircuitBreakerOpen":                  stats.state != overcurrent.StateClosed,
 		"propertyValue_circuitBreakerForceOpen": stats.state == overcurrent.StateHardOpen,
+
+		"propertyValue_circuitBreakerErrorThresholdPercentage": stats.config.ErrorThresholdPercentage,
+		"propertyValue_circuitBreakerRequestVolumeThreshold":   stats.config.RequestVolumeThreshold,
+		"propertyValue_executionIsolationStrategy":             isolation,
+
+		"propertyValue_fallbackIsolationSemaphoreMaxConcurrentRequests": stats.config.FallbackMaxConcurrency,
 	}
 
 	for k, v := range constantCommandProperties {
@@ -149,17 +170,40 @@ func makeCommandStats(name string, stats *FrozenBreakerStats) map[string]interfa
 }
 
 func makeThreadPoolStats(name string, stats *FrozenBreakerStats) map[string]interface{} {
+	var (
+		poolSize           = stats.config.MaxConcurrency
+		maximumPoolSize    = poolSize
+		rejectionThreshold = interface{}("NaN")
+		active             = stats.currents[overcurrent.EventTypeSemaphoreAcquired]
+		queued             = stats.currents[overcurrent.EventTypeSemaphoreQueued]
+		completed          = stats.currents[overcurrent.EventTypePoolTaskCompleted]
+	)
+
+	if limit, ok := stats.gauges[overcurrent.EventTypeConcurrencyLimit]; ok {
+		maximumPoolSize = int(limit)
+	}
+
+	if stats.config.PoolCoreSize > 0 {
+		poolSize = stats.config.PoolCoreSize
+		maximumPoolSize = poolSize
+		rejectionThreshold = stats.config.QueueSizeRejectionThreshold
+	}
+
 	properties := map[string]interface{}{
 		"type":                        "HystrixThreadPool",
 		"name":                        name,
-		"currentCorePoolSize":         stats.config.MaxConcurrency,
-		"currentLargestPoolSize":      stats.config.MaxConcurrency,
-		"currentMaximumPoolSize":      stats.config.MaxConcurrency,
-		"currentPoolSize":             stats.config.MaxConcurrency,
-		"currentActiveCount":          stats.currents[overcurrent.EventTypeSemaphoreAcquired],
+		"currentCorePoolSize":         poolSize,
+		"currentLargestPoolSize":      poolSize,
+		"currentMaximumPoolSize":      maximumPoolSize,
+		"currentPoolSize":             poolSize,
+		"currentActiveCount":          active,
 		"rollingMaxActiveThreads":     stats.maximums[overcurrent.EventTypeSemaphoreAcquired],
 		"rollingCountThreadsExecuted": stats.counters[overcurrent.EventTypeSemaphoreAcquired],
 		"currentQueueSize":            stats.maximums[overcurrent.EventTypeSemaphoreQueued],
+		"currentCompletedTaskCount":   completed,
+		"currentTaskCount":            completed + active + queued,
+
+		"propertyValue_queueSizeRejectionThreshold": rejectionThreshold,
 	}
 
 	for k, v := range constantThreadPoolProperties {
@@ -186,31 +230,21 @@ func makeLatencies(values []time.Duration) map[string]interface{} {
 var constantCommandProperties = map[string]interface{}{
 	"currentConcurrentExecutionCount":                                0,
 	"propertyValue_circuitBreakerEnabled":                            true,
-	"propertyValue_circuitBreakerErrorThresholdPercentage":           0,
 	"propertyValue_circuitBreakerForceClosed":                        false,
-	"propertyValue_circuitBreakerRequestVolumeThreshold":             0,
 	"propertyValue_circuitBreakerSleepWindowInMilliseconds":          0,
 	"propertyValue_executionIsolationSemaphoreMaxConcurrentRequests": 0,
-	"propertyValue_executionIsolationStrategy":                       "SEMAPHORE",
 	"propertyValue_executionIsolationThreadInterruptOnTimeout":       false,
 	"propertyValue_executionIsolationThreadPoolKeyOverride":          "",
 	"propertyValue_executionIsolationThreadTimeoutInMilliseconds":    "",
-	"propertyValue_fallbackIsolationSemaphoreMaxConcurrentRequests":  0,
 	"propertyValue_metricsRollingStatisticalWindowInMilliseconds":    10000,
 	"propertyValue_requestCacheEnabled":                              false,
 	"propertyValue_requestLogEnabled":                                false,
 	"reportingHosts":                                                 1,
 	"rollingCountCollapsedRequests":                                  0,
-	"rollingCountExceptionsThrown":                                   0,
-	"rollingCountFallbackRejection":                                  0,
 	"rollingCountResponsesFromCache":                                 0,
-	"rollingCountThreadPoolRejected":                                 0,
 }
 
 var constantThreadPoolProperties = map[string]interface{}{
-	"currentCompletedTaskCount":                                   15,
-	"currentTaskCount":                                            15,
 	"propertyValue_metricsRollingStatisticalWindowInMilliseconds": 10000,
-	"propertyValue_queueSizeRejectionThreshold":                   "NaN",
 	"reportingHosts":                                              1,
 }
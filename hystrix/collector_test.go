@@ -2,11 +2,118 @@ package hystrix
 
 import (
 	"github.com/aphistic/sweet"
+	"github.com/efritz/overcurrent"
 	. "github.com/onsi/gomega"
 )
 
 type CollectorSuite struct{}
 
-func (s *CollectorSuite) TestX(t sweet.T) {
-	Expect(true).To(BeTrue()) // TODO
+func (s *CollectorSuite) TestExceptionsThrown(t sweet.T) {
+	stats := NewBreakerStats(testConfig)
+
+	for i := 0; i < 3; i++ {
+		stats.Increment(overcurrent.EventTypePanic)
+	}
+
+	properties := makeCommandStats("test", stats.Freeze())
+	Expect(properties["rollingCountExceptionsThrown"]).To(Equal(3))
+}
+
+func (s *CollectorSuite) TestCircuitBreakerProperties(t sweet.T) {
+	stats := NewBreakerStats(overcurrent.BreakerConfig{
+		MaxConcurrency:           50,
+		ErrorThresholdPercentage: 40,
+		RequestVolumeThreshold:   25,
+	})
+
+	properties := makeCommandStats("test", stats.Freeze())
+	Expect(properties["propertyValue_circuitBreakerErrorThresholdPercentage"]).To(Equal(40))
+	Expect(properties["propertyValue_circuitBreakerRequestVolumeThreshold"]).To(Equal(25))
+}
+
+func (s *CollectorSuite) TestThreadPoolStats(t sweet.T) {
+	stats := NewBreakerStats(overcurrent.BreakerConfig{
+		MaxConcurrency:              50,
+		PoolCoreSize:                10,
+		PoolQueueSize:               20,
+		QueueSizeRejectionThreshold: 15,
+	})
+
+	for i := 0; i < 5; i++ {
+		stats.Increment(overcurrent.EventTypeSemaphoreAcquired)
+		stats.Increment(overcurrent.EventTypeSemaphoreReleased)
+		stats.Increment(overcurrent.EventTypePoolTaskCompleted)
+	}
+
+	stats.Increment(overcurrent.EventTypeSemaphoreAcquired)
+	stats.Increment(overcurrent.EventTypeSemaphoreQueued)
+	stats.Increment(overcurrent.EventTypeRejection)
+
+	frozen := stats.Freeze()
+
+	properties := makeThreadPoolStats("test", frozen)
+	Expect(properties["currentCorePoolSize"]).To(Equal(10))
+	Expect(properties["currentMaximumPoolSize"]).To(Equal(10))
+	Expect(properties["currentActiveCount"]).To(Equal(1))
+	Expect(properties["currentCompletedTaskCount"]).To(Equal(5))
+	Expect(properties["currentTaskCount"]).To(Equal(7))
+	Expect(properties["propertyValue_queueSizeRejectionThreshold"]).To(Equal(15))
+
+	properties = makeCommandStats("test", frozen)
+	Expect(properties["propertyValue_executionIsolationStrategy"]).To(Equal("THREAD"))
+	Expect(properties["rollingCountThreadPoolRejected"]).To(Equal(1))
+	Expect(properties["rollingCountSemaphoreRejected"]).To(Equal(0))
+}
+
+func (s *CollectorSuite) TestSemaphoreStats(t sweet.T) {
+	stats := NewBreakerStats(testConfig)
+	stats.Increment(overcurrent.EventTypeRejection)
+	stats.Increment(overcurrent.EventTypeRateLimited)
+
+	frozen := stats.Freeze()
+
+	properties := makeThreadPoolStats("test", frozen)
+	Expect(properties["currentCorePoolSize"]).To(Equal(testConfig.MaxConcurrency))
+	Expect(properties["propertyValue_queueSizeRejectionThreshold"]).To(Equal("NaN"))
+
+	properties = makeCommandStats("test", frozen)
+	Expect(properties["propertyValue_executionIsolationStrategy"]).To(Equal("SEMAPHORE"))
+	Expect(properties["rollingCountSemaphoreRejected"]).To(Equal(2))
+	Expect(properties["rollingCountThreadPoolRejected"]).To(Equal(0))
+}
+
+func (s *CollectorSuite) TestConcurrencyLimitStats(t sweet.T) {
+	stats := NewBreakerStats(testConfig)
+	stats.SetGauge(overcurrent.EventTypeConcurrencyLimit, 12)
+	stats.SetGauge(overcurrent.EventTypeConcurrencyLimit, 8)
+
+	properties := makeThreadPoolStats("test", stats.Freeze())
+	Expect(properties["currentCorePoolSize"]).To(Equal(testConfig.MaxConcurrency))
+	Expect(properties["currentMaximumPoolSize"]).To(Equal(8))
+}
+
+func (s *CollectorSuite) TestNamedGauges(t sweet.T) {
+	var (
+		collector = NewCollector()
+		named     = overcurrent.NamedCollector("test", collector)
+	)
+
+	named.ReportNew(testConfig)
+
+	gaugeCollector, ok := named.(overcurrent.GaugeCollector)
+	Expect(ok).To(BeTrue())
+	gaugeCollector.ReportGauge(overcurrent.EventTypeConcurrencyLimit, 8)
+
+	properties := makeThreadPoolStats("test", collector.getStats("test").Freeze())
+	Expect(properties["currentMaximumPoolSize"]).To(Equal(8))
+}
+
+func (s *CollectorSuite) TestFallbackStats(t sweet.T) {
+	stats := NewBreakerStats(overcurrent.BreakerConfig{FallbackMaxConcurrency: 10})
+	stats.Increment(overcurrent.EventTypeFallbackRejection)
+	stats.Increment(overcurrent.EventTypeFallbackRejection)
+
+	properties := makeCommandStats("test", stats.Freeze())
+	Expect(properties["rollingCountFallbackRejection"]).To(Equal(2))
+	Expect(properties["propertyValue_fallbackIsolationSemaphoreMaxConcurrentRequests"]).To(Equal(10))
 }
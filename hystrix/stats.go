@@ -13,6 +13,7 @@ type (
 	BreakerStats struct {
 		config  overcurrent.BreakerConfig
 		state   overcurrent.CircuitState
+		gauges  map[overcurrent.EventType]float64
 		buckets map[int64]*bucket
 		mutex   sync.RWMutex
 		clock   glock.Clock
@@ -32,6 +33,7 @@ type (
 		durations map[overcurrent.EventType][]time.Duration
 		currents  map[overcurrent.EventType]int
 		maximums  map[overcurrent.EventType]int
+		gauges    map[overcurrent.EventType]float64
 	}
 
 	dualStatRelation struct {
@@ -54,6 +56,7 @@ func NewBreakerStats(config overcurrent.BreakerConfig) *BreakerStats {
 func newBreakerStatsWithClock(config overcurrent.BreakerConfig, clock glock.Clock) *BreakerStats {
 	return &BreakerStats{
 		config:  config,
+		gauges:  map[overcurrent.EventType]float64{},
 		buckets: map[int64]*bucket{},
 		clock:   clock,
 	}
@@ -65,6 +68,12 @@ func (s *BreakerStats) SetState(state overcurrent.CircuitState) {
 	s.mutex.Unlock()
 }
 
+func (s *BreakerStats) SetGauge(eventType overcurrent.EventType, value float64) {
+	s.mutex.Lock()
+	s.gauges[eventType] = value
+	s.mutex.Unlock()
+}
+
 func (s *BreakerStats) Increment(eventType overcurrent.EventType) {
 	var (
 		dualType = eventType
@@ -166,6 +175,7 @@ func (s *BreakerStats) Freeze() *FrozenBreakerStats {
 		durations: sortDurationMap(durations),
 		currents:  currents,
 		maximums:  maximums,
+		gauges:    cloneGaugeMap(s.gauges),
 	}
 }
 
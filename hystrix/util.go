@@ -40,6 +40,15 @@ func cloneMap(values map[overcurrent.EventType]int) map[overcurrent.EventType]in
 	return clone
 }
 
+func cloneGaugeMap(values map[overcurrent.EventType]float64) map[overcurrent.EventType]float64 {
+	clone := map[overcurrent.EventType]float64{}
+	for k, v := range values {
+		clone[k] = v
+	}
+
+	return clone
+}
+
 func mean(values []time.Duration) time.Duration {
 	if len(values) == 0 {
 		return 0
@@ -0,0 +1,213 @@
+package overcurrent
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+type (
+	// ConcurrencyLimiter determines the maximum number of concurrent invocations
+	// of a breaker in a registry. Implementations may resize the limit based on
+	// the calls observed by the registry. A limiter is stateful and should not be
+	// shared between breakers.
+	ConcurrencyLimiter interface {
+		// Limit returns the current maximum number of concurrent invocations.
+		Limit() int
+
+		// Observe is invoked after each invocation completes with the duration
+		// of the invocation, the number of invocations in-flight when it began
+		// (including itself), and whether or not the invocation was dropped
+		// (i.e. it timed out).
+		Observe(rtt time.Duration, inFlight int, dropped bool)
+	}
+
+	fixedConcurrencyLimiter struct {
+		limit int
+	}
+
+	// boundedLimit is the state shared by the adaptive limiters.
+	boundedLimit struct {
+		mutex sync.Mutex
+		limit float64
+		min   float64
+		max   float64
+	}
+
+	aimdConcurrencyLimiter struct {
+		boundedLimit
+		backoffRatio float64
+	}
+
+	vegasConcurrencyLimiter struct {
+		boundedLimit
+		rttNoLoad time.Duration
+	}
+
+	gradientConcurrencyLimiter struct {
+		boundedLimit
+		window    int
+		tolerance float64
+		longRTT   float64
+	}
+)
+
+// gradientSmoothing is the weight given to a new limit computed by a gradient
+// limiter relative to the current limit.
+const gradientSmoothing = 0.2
+
+// NewFixedConcurrencyLimiter creates a limiter which never changes its limit.
+func NewFixedConcurrencyLimiter(limit int) ConcurrencyLimiter {
+	return &fixedConcurrencyLimiter{limit: limit}
+}
+
+func (l *fixedConcurrencyLimiter) Limit() int {
+	return l.limit
+}
+
+func (l *fixedConcurrencyLimiter) Observe(rtt time.Duration, inFlight int, dropped bool) {}
+
+// NewAIMDConcurrencyLimiter creates a limiter which increases its limit by one after
+// each invocation which completes while at least half of the limit is in use, and
+// multiplies its limit by backoffRatio after each dropped invocation. The limit stays
+// within the bounds [min, max].
+func NewAIMDConcurrencyLimiter(initial, min, max int, backoffRatio float64) ConcurrencyLimiter {
+	l := &aimdConcurrencyLimiter{backoffRatio: backoffRatio}
+	l.init(initial, min, max)
+	return l
+}
+
+func (l *aimdConcurrencyLimiter) Observe(rtt time.Duration, inFlight int, dropped bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if dropped {
+		l.set(l.limit * l.backoffRatio)
+	} else if float64(inFlight)*2 >= l.limit {
+		l.set(l.limit + 1)
+	}
+}
+
+// NewVegasConcurrencyLimiter creates a limiter modeled after TCP Vegas congestion
+// control. The smallest duration observed is taken as the latency of an unloaded
+// dependency, and the number of invocations queued by the dependency is estimated
+// by comparing each duration against it. The limit grows quickly while the queue is
+// small and shrinks as the queue grows or when an invocation is dropped. The limit
+// stays within the bounds [min, max].
+func NewVegasConcurrencyLimiter(initial, min, max int) ConcurrencyLimiter {
+	l := &vegasConcurrencyLimiter{}
+	l.init(initial, min, max)
+	return l
+}
+
+func (l *vegasConcurrencyLimiter) Observe(rtt time.Duration, inFlight int, dropped bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if rtt <= 0 {
+		return
+	}
+
+	if l.rttNoLoad == 0 || rtt < l.rttNoLoad {
+		l.rttNoLoad = rtt
+	}
+
+	step := math.Max(1, math.Log10(l.limit))
+
+	if dropped {
+		l.set(l.limit - step)
+		return
+	}
+
+	if float64(inFlight)*2 < l.limit {
+		// Not enough load to judge the limit
+		return
+	}
+
+	var (
+		queueSize = math.Ceil(l.limit * (1 - float64(l.rttNoLoad)/float64(rtt)))
+		alpha     = 3 * step
+		beta      = 6 * step
+	)
+
+	switch {
+	case queueSize <= step:
+		l.set(l.limit + beta)
+	case queueSize < alpha:
+		l.set(l.limit + step)
+	case queueSize > beta:
+		l.set(l.limit - step)
+	}
+}
+
+// NewGradientConcurrencyLimiter creates a limiter which adjusts its limit by the
+// gradient between the long-term average duration (an exponential moving average
+// over roughly window invocations) and the duration of each invocation. Durations
+// up to tolerance times the average do not shrink the limit. The square root of the
+// limit is added as headroom so that the limit can grow, and dropped invocations
+// halve the gradient. The limit stays within the bounds [min, max].
+func NewGradientConcurrencyLimiter(initial, min, max, window int, tolerance float64) ConcurrencyLimiter {
+	if window < 1 {
+		window = 1
+	}
+
+	l := &gradientConcurrencyLimiter{window: window, tolerance: tolerance}
+	l.init(initial, min, max)
+	return l
+}
+
+func (l *gradientConcurrencyLimiter) Observe(rtt time.Duration, inFlight int, dropped bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if rtt <= 0 {
+		return
+	}
+
+	if l.longRTT == 0 {
+		l.longRTT = float64(rtt)
+	} else {
+		l.longRTT += (float64(rtt) - l.longRTT) / float64(l.window)
+	}
+
+	gradient := math.Max(0.5, math.Min(1, l.tolerance*l.longRTT/float64(rtt)))
+	if dropped {
+		gradient = 0.5
+	}
+
+	if gradient == 1 && float64(inFlight)*2 < l.limit {
+		// Not enough load to judge the limit
+		return
+	}
+
+	newLimit := l.limit*gradient + math.Sqrt(l.limit)
+	l.set(l.limit*(1-gradientSmoothing) + newLimit*gradientSmoothing)
+}
+
+// init sets the bounds and the initial value of the limit.
+func (b *boundedLimit) init(initial, min, max int) {
+	if min < 1 {
+		min = 1
+	}
+
+	if max < min {
+		max = min
+	}
+
+	b.min = float64(min)
+	b.max = float64(max)
+	b.set(float64(initial))
+}
+
+func (b *boundedLimit) Limit() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return int(b.limit)
+}
+
+// set updates the limit, clamped to the bounds of the limiter. This method
+// assumes the mutex is held.
+func (b *boundedLimit) set(limit float64) {
+	b.limit = math.Max(b.min, math.Min(b.max, limit))
+}
@@ -0,0 +1,123 @@
+package overcurrent
+
+import (
+	"time"
+
+	"github.com/aphistic/sweet"
+	. "github.com/onsi/gomega"
+)
+
+type LimiterSuite struct{}
+
+func (s *LimiterSuite) TestFixed(t sweet.T) {
+	limiter := NewFixedConcurrencyLimiter(10)
+	Expect(limiter.Limit()).To(Equal(10))
+
+	limiter.Observe(time.Millisecond, 10, false)
+	limiter.Observe(time.Second, 10, true)
+	Expect(limiter.Limit()).To(Equal(10))
+}
+
+func (s *LimiterSuite) TestAIMD(t sweet.T) {
+	limiter := NewAIMDConcurrencyLimiter(10, 5, 12, 0.5)
+	Expect(limiter.Limit()).To(Equal(10))
+
+	limiter.Observe(time.Millisecond, 5, false)
+	Expect(limiter.Limit()).To(Equal(11))
+
+	// Not enough load to increase
+	limiter.Observe(time.Millisecond, 1, false)
+	Expect(limiter.Limit()).To(Equal(11))
+
+	limiter.Observe(time.Millisecond, 11, false)
+	limiter.Observe(time.Millisecond, 12, false)
+	Expect(limiter.Limit()).To(Equal(12))
+
+	limiter.Observe(time.Second, 12, true)
+	Expect(limiter.Limit()).To(Equal(6))
+
+	limiter.Observe(time.Second, 6, true)
+	Expect(limiter.Limit()).To(Equal(5))
+}
+
+func (s *LimiterSuite) TestVegas(t sweet.T) {
+	limiter := NewVegasConcurrencyLimiter(10, 1, 100)
+	Expect(limiter.Limit()).To(Equal(10))
+
+	// No queueing at the unloaded latency
+	limiter.Observe(10*time.Millisecond, 10, false)
+	Expect(limiter.Limit()).To(Equal(16))
+
+	// Heavy queueing
+	limiter.Observe(40*time.Millisecond, 16, false)
+	Expect(limiter.Limit()).To(Equal(14))
+
+	limiter.Observe(10*time.Millisecond, 14, true)
+	Expect(limiter.Limit()).To(Equal(13))
+
+	// Not enough load to judge
+	limiter.Observe(10*time.Millisecond, 1, false)
+	Expect(limiter.Limit()).To(Equal(13))
+}
+
+func (s *LimiterSuite) TestVegasBounds(t sweet.T) {
+	limiter := NewVegasConcurrencyLimiter(10, 8, 12)
+
+	limiter.Observe(10*time.Millisecond, 10, false)
+	Expect(limiter.Limit()).To(Equal(12))
+
+	for i := 0; i < 10; i++ {
+		limiter.Observe(time.Second, 12, true)
+	}
+
+	Expect(limiter.Limit()).To(Equal(8))
+}
+
+func (s *LimiterSuite) TestGradient(t sweet.T) {
+	limiter := NewGradientConcurrencyLimiter(10, 1, 100, 10, 2)
+	Expect(limiter.Limit()).To(Equal(10))
+
+	for i := 0; i < 10; i++ {
+		limiter.Observe(10*time.Millisecond, limiter.Limit(), false)
+	}
+
+	grown := limiter.Limit()
+	Expect(grown).To(BeNumerically(">", 10))
+
+	// Not enough load to judge
+	limiter.Observe(10*time.Millisecond, 1, false)
+	Expect(limiter.Limit()).To(Equal(grown))
+
+	// Within tolerance of the average
+	limiter.Observe(15*time.Millisecond, grown, false)
+	Expect(limiter.Limit()).To(BeNumerically(">=", grown))
+
+	for i := 0; i < 5; i++ {
+		limiter.Observe(200*time.Millisecond, grown, false)
+	}
+
+	Expect(limiter.Limit()).To(BeNumerically("<", grown))
+
+	shrunk := limiter.Limit()
+	for i := 0; i < 5; i++ {
+		limiter.Observe(10*time.Millisecond, shrunk, true)
+	}
+
+	Expect(limiter.Limit()).To(BeNumerically("<", shrunk))
+}
+
+func (s *LimiterSuite) TestGradientBounds(t sweet.T) {
+	limiter := NewGradientConcurrencyLimiter(10, 5, 11, 10, 2)
+
+	for i := 0; i < 20; i++ {
+		limiter.Observe(10*time.Millisecond, 10, false)
+	}
+
+	Expect(limiter.Limit()).To(Equal(11))
+
+	for i := 0; i < 100; i++ {
+		limiter.Observe(10*time.Millisecond, 10, true)
+	}
+
+	Expect(limiter.Limit()).To(Equal(5))
+}
@@ -0,0 +1,90 @@
+package overcurrent
+
+import "time"
+
+type (
+	// StateChange describes a transition of a breaker from one state to another.
+	StateChange struct {
+		Name   string
+		From   CircuitState
+		To     CircuitState
+		Time   time.Time
+		Reason StateChangeReason
+	}
+
+	// StateChangeListener is invoked each time a breaker changes state. Listeners
+	// are invoked synchronously, but outside of the breaker's critical section, so
+	// it is safe to query the breaker from a listener.
+	StateChangeListener func(StateChange)
+
+	// StateChangeReason distinguishes the causes of a state change.
+	StateChangeReason int
+)
+
+const (
+	_ StateChangeReason = iota
+
+	// StateChangeReasonTripped occurs when the trip condition is satisfied
+	// and the breaker opens. The change is reported as soon as the failure
+	// which satisfies the trip condition is recorded.
+	StateChangeReasonTripped
+
+	// StateChangeReasonTripConditionCleared occurs when the trip condition is
+	// no longer satisfied (e.g. failures have fallen out of a window) and the
+	// breaker closes.
+	StateChangeReasonTripConditionCleared
+
+	// StateChangeReasonManualTrip occurs when Trip is called.
+	StateChangeReasonManualTrip
+
+	// StateChangeReasonManualReset occurs when Reset is called.
+	StateChangeReasonManualReset
+
+	// StateChangeReasonResetTimeoutElapsed occurs when the breaker has been
+	// open for long enough to transition into the half-closed state.
+	StateChangeReasonResetTimeoutElapsed
+
+	// StateChangeReasonProbeSucceeded occurs when a call succeeds while the
+	// breaker is not closed and the breaker closes.
+	StateChangeReasonProbeSucceeded
+
+	// StateChangeReasonProbeFailed occurs when a call fails while the breaker
+	// is half-closed and the breaker re-opens.
+	StateChangeReasonProbeFailed
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case StateOpen:
+		return "open"
+	case StateClosed:
+		return "closed"
+	case StateHalfClosed:
+		return "half-closed"
+	case StateHardOpen:
+		return "hard-open"
+	}
+
+	return "unknown"
+}
+
+func (r StateChangeReason) String() string {
+	switch r {
+	case StateChangeReasonTripped:
+		return "tripped"
+	case StateChangeReasonTripConditionCleared:
+		return "trip condition cleared"
+	case StateChangeReasonManualTrip:
+		return "manual trip"
+	case StateChangeReasonManualReset:
+		return "manual reset"
+	case StateChangeReasonResetTimeoutElapsed:
+		return "reset timeout elapsed"
+	case StateChangeReasonProbeSucceeded:
+		return "probe succeeded"
+	case StateChangeReasonProbeFailed:
+		return "probe failed"
+	}
+
+	return "unknown"
+}
@@ -14,11 +14,24 @@ func TestMain(m *testing.M) {
 	sweet.Run(m, func(s *sweet.S) {
 		s.RegisterPlugin(junit.NewPlugin())
 
+		s.AddSuite(&AdaptiveSuite{})
+		s.AddSuite(&BudgetSuite{})
+		s.AddSuite(&ThrottleSuite{})
 		s.AddSuite(&TripSuite{})
+		s.AddSuite(&TripCompositeSuite{})
 		s.AddSuite(&FailureSuite{})
+		s.AddSuite(&ErrorsSuite{})
+		s.AddSuite(&FallbackSuite{})
+		s.AddSuite(&MetricsSuite{})
+		s.AddSuite(&ContextSuite{})
 		s.AddSuite(&BreakerSuite{})
+		s.AddSuite(&ExecuteSuite{})
 		s.AddSuite(&RegistrySuite{})
+		s.AddSuite(&PoolSuite{})
+		s.AddSuite(&LimiterSuite{})
+		s.AddSuite(&RateLimitSuite{})
 		s.AddSuite(&SemaphoreSuite{})
 		s.AddSuite(&UtilSuite{})
+		s.AddSuite(&WindowSuite{})
 	})
 }
@@ -19,11 +19,34 @@ type (
 		ReportState(CircuitState)
 	}
 
+	// GaugeCollector may be implemented by a MetricCollector which tracks
+	// gauges. Gauge events are not sent to collectors which do not implement
+	// this interface.
+	GaugeCollector interface {
+		// ReportGauge fires when a value which is tracked over time (such as
+		// the current concurrency limit of a breaker) changes.
+		ReportGauge(EventType, float64)
+	}
+
 	// BreakerConfig is a struct that contains a copy of some of a breaker's
 	// initialization values. This struct may grow as metric collectors track
 	// additional breaker state.
 	BreakerConfig struct {
 		MaxConcurrency int
+
+		// FallbackMaxConcurrency is zero when fallbacks are unbounded.
+		FallbackMaxConcurrency int
+
+		// PoolCoreSize, PoolQueueSize, and QueueSizeRejectionThreshold are
+		// set when the breaker runs calls on a thread pool.
+		PoolCoreSize                int
+		PoolQueueSize               int
+		QueueSizeRejectionThreshold int
+
+		// ErrorThresholdPercentage and RequestVolumeThreshold are set when
+		// the trip condition is a RollingErrorPercentageTripCondition.
+		ErrorThresholdPercentage int
+		RequestVolumeThreshold   int
 	}
 
 	// EventType distinguishes interesting occurrences.
@@ -38,7 +61,9 @@ const (
 	EventTypeSuccess
 
 	// EventTypeFailure occurs when a breaker func returns a non-nil error
-	// or cannot be called due to breaker status or semaphore contention.
+	// or cannot be called due to breaker status, semaphore contention, or
+	// the rate limit. Failures caused by the caller's context are not
+	// included.
 	EventTypeFailure
 
 	// EventTypeError occurs when a breaker func returns a non-nil error
@@ -57,7 +82,7 @@ const (
 	EventTypeTimeout
 
 	// EventTypeRejection occurs when a breaker func cannot be invoked due
-	// to semaphore contention.
+	// to semaphore contention or too many abandoned invocations.
 	EventTypeRejection
 
 	// EventTypeFallbackSuccess occurs when a fallback func returns a nil
@@ -89,5 +114,86 @@ const (
 	EventTypeSemaphoreAcquired
 
 	// EventTypeSemaphoreReleased occurs after the breaker func is invoked.
+	//
+	// For breakers with a thread pool, the semaphore events describe the pool
+	// instead: a breaker func is queued while waiting for a worker and holds a
+	// token while a worker runs it.
 	EventTypeSemaphoreReleased
+
+	// EventTypeCancelled occurs when a breaker func fails or cannot be invoked
+	// because the caller's context was canceled. This event is not counted as
+	// a failure against the breaker. A call which fails because the deadline of
+	// the caller's context elapsed is reported as a timeout or rejection instead.
+	EventTypeCancelled
+
+	// EventTypePanic occurs when a breaker func panics. The panic is converted
+	// into a PanicError, which is then interpreted like any other error.
+	EventTypePanic
+
+	// EventTypeAbandoned occurs when a breaker stops waiting for a breaker
+	// func which is still running (due to a timeout or cancellation).
+	EventTypeAbandoned
+
+	// EventTypeAbandonedCompleted occurs when an abandoned breaker func
+	// finally returns.
+	EventTypeAbandonedCompleted
+
+	// EventTypeAbandonedDuration marks the duration between the time a breaker
+	// func is abandoned and the time it finally returns.
+	EventTypeAbandonedDuration
+
+	// EventTypeWouldShortCircuit occurs when a breaker in dry-run mode invokes
+	// a breaker func which would otherwise have been short-circuited.
+	EventTypeWouldShortCircuit
+
+	// EventTypeInvocationTimeout marks the invocation timeout of a breaker
+	// with an adaptive invocation timeout. This event occurs when the breaker
+	// is created and each time the timeout changes.
+	EventTypeInvocationTimeout
+
+	// EventTypeRetry occurs each time a failed breaker func is attempted
+	// again due to the breaker's retry policy.
+	EventTypeRetry
+
+	// EventTypeRetryBudgetExhausted occurs when a failed breaker func is not
+	// attempted again because the retry budget is exhausted.
+	EventTypeRetryBudgetExhausted
+
+	// EventTypeHedge occurs when a registry starts a second attempt of a
+	// breaker func which has not completed within the hedge delay.
+	EventTypeHedge
+
+	// EventTypePoolTaskCompleted occurs when a worker of a breaker's thread
+	// pool finishes running a breaker func.
+	EventTypePoolTaskCompleted
+
+	// EventTypeConcurrencyLimit marks the current concurrency limit of a
+	// breaker in a registry. This gauge is reported when the breaker is
+	// configured and each time the limit changes.
+	EventTypeConcurrencyLimit
+
+	// EventTypeRateLimited occurs when a breaker func cannot be invoked
+	// because the breaker is over its rate limit.
+	EventTypeRateLimited
+
+	// EventTypeRejectProbability marks the probability with which a breaker
+	// with adaptive throttling rejects calls. This gauge is reported when the
+	// breaker is created and each time the probability changes.
+	EventTypeRejectProbability
+
+	// EventTypeFallbackRejection occurs when a fallback func is not invoked
+	// because the breaker is running its maximum number of fallbacks.
+	EventTypeFallbackRejection
+
+	// EventTypeFallbackDuration marks the duration of a fallback func
+	// invocation.
+	EventTypeFallbackDuration
 )
+
+// reportGauge sends a gauge event to the given collector if it implements
+// GaugeCollector.
+func reportGauge(collector MetricCollector, eventType EventType, value float64) {
+	if gaugeCollector, ok := collector.(GaugeCollector); ok {
+		gaugeCollector.ReportGauge(eventType, value)
+	}
+}
@@ -0,0 +1,67 @@
+package overcurrent
+
+import (
+	"time"
+
+	"github.com/aphistic/sweet"
+	. "github.com/onsi/gomega"
+)
+
+type (
+	MetricsSuite struct{}
+
+	// countingCollector counts the events it receives. It does not
+	// implement GaugeCollector.
+	countingCollector struct {
+		events int
+	}
+
+	countingNamedCollector struct {
+		events int
+	}
+)
+
+func (s *MetricsSuite) TestCollectorWithoutGauges(t sweet.T) {
+	var (
+		collector = &countingCollector{}
+		breaker   = NewCircuitBreaker(
+			testConfig(),
+			WithCollector(collector),
+			WithAdaptiveThrottling(2, time.Minute),
+		)
+	)
+
+	Expect(breaker.Call(nilFunc)).To(BeNil())
+	Expect(collector.events).To(BeNumerically(">", 0))
+}
+
+func (s *MetricsSuite) TestMultiCollectorGauges(t sweet.T) {
+	var (
+		collector = newTestCollector()
+		multi     = NewMultiCollector(&countingCollector{}, collector)
+	)
+
+	reportGauge(multi, EventTypeConcurrencyLimit, 3)
+	Expect(collector.gauges(EventTypeConcurrencyLimit)).To(Equal([]float64{3}))
+}
+
+func (s *MetricsSuite) TestNamedCollectorWithoutGauges(t sweet.T) {
+	var (
+		collector = &countingNamedCollector{}
+		named     = NamedCollector("test", collector)
+	)
+
+	reportGauge(named, EventTypeConcurrencyLimit, 3)
+	named.ReportCount(EventTypeAttempt)
+	Expect(collector.events).To(Equal(1))
+}
+
+func (c *countingCollector) ReportNew(BreakerConfig)                 { c.events++ }
+func (c *countingCollector) ReportCount(EventType)                   { c.events++ }
+func (c *countingCollector) ReportDuration(EventType, time.Duration) { c.events++ }
+func (c *countingCollector) ReportState(CircuitState)                { c.events++ }
+
+func (c *countingNamedCollector) ReportNew(string, BreakerConfig)                 { c.events++ }
+func (c *countingNamedCollector) ReportCount(string, EventType)                   { c.events++ }
+func (c *countingNamedCollector) ReportDuration(string, EventType, time.Duration) { c.events++ }
+func (c *countingNamedCollector) ReportState(string, CircuitState)                { c.events++ }
@@ -39,3 +39,9 @@ func (c *MultiCollector) ReportState(state CircuitState) {
 		collector.ReportState(state)
 	}
 }
+
+func (c *MultiCollector) ReportGauge(eventType EventType, value float64) {
+	for _, collector := range c.collectors {
+		reportGauge(collector, eventType, value)
+	}
+}
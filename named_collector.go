@@ -21,6 +21,14 @@ type (
 		ReportState(string, CircuitState)
 	}
 
+	// NamedGaugeCollector may be implemented by a NamedMetricCollector which
+	// tracks gauges.
+	NamedGaugeCollector interface {
+		// ReportGauge is GaugeCollector.ReportGauge with the name of the breaker
+		// passed in as a first argument.
+		ReportGauge(string, EventType, float64)
+	}
+
 	namedCollector struct {
 		name      string
 		collector NamedMetricCollector
@@ -52,3 +60,9 @@ func (c *namedCollector) ReportDuration(eventType EventType, duration time.Durat
 func (c *namedCollector) ReportState(state CircuitState) {
 	c.collector.ReportState(c.name, state)
 }
+
+func (c *namedCollector) ReportGauge(eventType EventType, value float64) {
+	if gaugeCollector, ok := c.collector.(NamedGaugeCollector); ok {
+		gaugeCollector.ReportGauge(c.name, eventType, value)
+	}
+}
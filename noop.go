@@ -20,12 +20,16 @@ func NewNoopBreaker() CircuitBreaker {
 	return &NoopBreaker{}
 }
 
-func (b *NoopBreaker) Trip()                                {}
-func (b *NoopBreaker) Reset()                               {}
-func (b *NoopBreaker) ShouldTry() bool                      { return true }
-func (b *NoopBreaker) MarkResult(err error) bool            { return true }
-func (b *NoopBreaker) Call(f BreakerFunc) error             { return f(context.Background()) }
-func (b *NoopBreaker) CallAsync(f BreakerFunc) <-chan error { return nil }
+func (b *NoopBreaker) Trip()                                                {}
+func (b *NoopBreaker) Reset()                                               {}
+func (b *NoopBreaker) ShouldTry() bool                                      { return true }
+func (b *NoopBreaker) State() CircuitState                                  { return StateClosed }
+func (b *NoopBreaker) Snapshot() Snapshot                                   { return Snapshot{State: StateClosed} }
+func (b *NoopBreaker) MarkResult(err error) bool                            { return true }
+func (b *NoopBreaker) Call(f BreakerFunc) error                             { return f(context.Background()) }
+func (b *NoopBreaker) CallContext(ctx context.Context, f BreakerFunc) error { return f(ctx) }
+func (b *NoopBreaker) CallAsync(f BreakerFunc) <-chan error                 { return nil }
+func (b *NoopBreaker) Close() error                                         { return nil }
 
 // NewNoopCollector creates a new do-nothing collector.
 func NewNoopCollector() MetricCollector {
@@ -36,3 +40,4 @@ func (c *NoopCollector) ReportNew(BreakerConfig)                 {}
 func (c *NoopCollector) ReportCount(EventType)                   {}
 func (c *NoopCollector) ReportDuration(EventType, time.Duration) {}
 func (c *NoopCollector) ReportState(CircuitState)                {}
+func (c *NoopCollector) ReportGauge(EventType, float64)          {}
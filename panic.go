@@ -0,0 +1,35 @@
+package overcurrent
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is returned by a breaker when the protected function panics. It
+// is interpreted as any other error returned from the protected function.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value interface{}
+
+	// Stack is the stack trace of the panicking goroutine.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("breaker func panicked: %v", e.Value)
+}
+
+// recoverPanics wraps the given function so that a panic is converted into
+// a PanicError instead of crashing the goroutine invoking the function.
+func recoverPanics(f BreakerFunc) BreakerFunc {
+	return func(ctx context.Context) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = &PanicError{Value: r, Stack: debug.Stack()}
+			}
+		}()
+
+		return f(ctx)
+	}
+}
@@ -0,0 +1,181 @@
+package overcurrent
+
+import (
+	"context"
+	"sync"
+)
+
+type (
+	// workerPool runs breaker functions on a fixed number of worker goroutines.
+	// Calls which arrive while every worker is busy wait in a bounded queue. A
+	// call must reserve a place in the pool before it is submitted so that it
+	// can be rejected before the breaker attempts it. A call whose context is
+	// done by the time it reaches a worker is not run. The workers run until the
+	// pool is closed.
+	workerPool struct {
+		mutex     sync.Mutex
+		tasks     chan *poolTask
+		workers   sync.WaitGroup
+		collector MetricCollector
+		coreSize  int
+		capacity  int
+		reserved  int
+		active    int
+		closed    bool
+	}
+
+	poolTask struct {
+		ctx    context.Context
+		f      func() error
+		ch     chan error
+		queued bool
+	}
+
+	// poolSlot is a reserved place in a worker pool.
+	poolSlot struct {
+		pool      *workerPool
+		submitted bool
+	}
+)
+
+// newWorkerPool creates a pool with coreSize workers. At most the minimum of
+// queueSize and rejectionThreshold calls may wait for a worker. A threshold of
+// zero or less is ignored.
+func newWorkerPool(coreSize, queueSize, rejectionThreshold int, collector MetricCollector) *workerPool {
+	if coreSize < 1 {
+		coreSize = 1
+	}
+
+	if rejectionThreshold > 0 && rejectionThreshold < queueSize {
+		queueSize = rejectionThreshold
+	}
+
+	if queueSize < 0 {
+		queueSize = 0
+	}
+
+	p := &workerPool{
+		tasks:     make(chan *poolTask, coreSize+queueSize),
+		collector: collector,
+		coreSize:  coreSize,
+		capacity:  coreSize + queueSize,
+	}
+
+	p.workers.Add(coreSize)
+	for i := 0; i < coreSize; i++ {
+		go p.work()
+	}
+
+	return p
+}
+
+// reserve returns a slot in the pool, or nil if the pool and its queue are full
+// or the pool is closed.
+func (p *workerPool) reserve() *poolSlot {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.closed || p.reserved+p.active >= p.capacity {
+		return nil
+	}
+
+	p.reserved++
+	return &poolSlot{pool: p}
+}
+
+// close rejects further calls and stops the workers once every call which has
+// already reserved a slot has been run. This method does not wait for the workers
+// to stop.
+func (p *workerPool) close() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.closed {
+		return
+	}
+
+	p.closed = true
+	p.stopIfDrained()
+}
+
+// stopIfDrained stops the workers if the pool is closed and no slots remain
+// reserved, so that nothing more can be submitted. This method assumes the
+// mutex is held.
+func (p *workerPool) stopIfDrained() {
+	if p.closed && p.reserved == 0 {
+		close(p.tasks)
+	}
+}
+
+func (p *workerPool) work() {
+	defer p.workers.Done()
+
+	for task := range p.tasks {
+		// A call whose context is already done is not run
+		err := task.ctx.Err()
+
+		p.mutex.Lock()
+		p.reserved--
+		if err == nil {
+			p.active++
+		}
+		p.stopIfDrained()
+		p.mutex.Unlock()
+
+		if task.queued {
+			p.collector.ReportCount(EventTypeSemaphoreDequeued)
+		}
+
+		if err != nil {
+			task.ch <- err
+			close(task.ch)
+			continue
+		}
+
+		p.collector.ReportCount(EventTypeSemaphoreAcquired)
+		task.ch <- task.f()
+		close(task.ch)
+
+		p.mutex.Lock()
+		p.active--
+		p.mutex.Unlock()
+
+		p.collector.ReportCount(EventTypeSemaphoreReleased)
+		p.collector.ReportCount(EventTypePoolTaskCompleted)
+	}
+}
+
+// spawn runs the given function on a worker of the pool and returns a channel
+// which receives its result. If the given context is done before a worker picks
+// up the function, the function is not run and the channel receives the error of
+// the context instead. A slot can be used to spawn only one function.
+func (s *poolSlot) spawn(ctx context.Context, f func() error) <-chan error {
+	s.pool.mutex.Lock()
+	task := &poolTask{
+		ctx:    ctx,
+		f:      f,
+		ch:     make(chan error, 1),
+		queued: s.pool.active+s.pool.reserved > s.pool.coreSize,
+	}
+	s.pool.mutex.Unlock()
+
+	if task.queued {
+		s.pool.collector.ReportCount(EventTypeSemaphoreQueued)
+	}
+
+	s.submitted = true
+	s.pool.tasks <- task
+	return task.ch
+}
+
+// release frees the slot if no function was spawned with it.
+func (s *poolSlot) release() {
+	if s.submitted {
+		return
+	}
+
+	s.pool.mutex.Lock()
+	s.pool.reserved--
+	s.pool.stopIfDrained()
+	s.pool.mutex.Unlock()
+}
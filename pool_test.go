@@ -0,0 +1,152 @@
+package overcurrent
+
+import (
+	"context"
+
+	"github.com/aphistic/sweet"
+	. "github.com/onsi/gomega"
+)
+
+type PoolSuite struct{}
+
+func (s *PoolSuite) TestReserve(t sweet.T) {
+	pool := newWorkerPool(2, 3, 0, newTestCollector())
+
+	slots := []*poolSlot{}
+	for i := 0; i < 5; i++ {
+		slot := pool.reserve()
+		Expect(slot).NotTo(BeNil())
+		slots = append(slots, slot)
+	}
+
+	Expect(pool.reserve()).To(BeNil())
+
+	slots[0].release()
+	Expect(pool.reserve()).NotTo(BeNil())
+}
+
+func (s *PoolSuite) TestRejectionThreshold(t sweet.T) {
+	pool := newWorkerPool(2, 10, 1, newTestCollector())
+
+	for i := 0; i < 3; i++ {
+		Expect(pool.reserve()).NotTo(BeNil())
+	}
+
+	Expect(pool.reserve()).To(BeNil())
+}
+
+func (s *PoolSuite) TestSpawn(t sweet.T) {
+	var (
+		collector = newTestCollector()
+		pool      = newWorkerPool(1, 1, 0, collector)
+		block     = make(chan struct{})
+	)
+
+	ch1 := pool.reserve().spawn(context.Background(), func() error {
+		<-block
+		return testErr
+	})
+
+	Eventually(func() int { return collector.count(EventTypeSemaphoreAcquired) }).Should(Equal(1))
+
+	ch2 := pool.reserve().spawn(context.Background(), func() error {
+		return nil
+	})
+
+	Expect(pool.reserve()).To(BeNil())
+	Expect(collector.count(EventTypeSemaphoreQueued)).To(Equal(1))
+	Consistently(ch2).ShouldNot(Receive())
+
+	close(block)
+	Eventually(ch1).Should(Receive(Equal(testErr)))
+	Eventually(ch2).Should(Receive(BeNil()))
+	Eventually(func() int { return collector.count(EventTypePoolTaskCompleted) }).Should(Equal(2))
+
+	Expect(collector.count(EventTypeSemaphoreDequeued)).To(Equal(1))
+	Expect(collector.count(EventTypeSemaphoreAcquired)).To(Equal(2))
+	Expect(collector.count(EventTypeSemaphoreReleased)).To(Equal(2))
+	Expect(pool.reserve()).NotTo(BeNil())
+}
+
+func (s *PoolSuite) TestSpawnDoneContext(t sweet.T) {
+	var (
+		collector   = newTestCollector()
+		pool        = newWorkerPool(1, 1, 0, collector)
+		block       = make(chan struct{})
+		ctx, cancel = context.WithCancel(context.Background())
+		called      = false
+	)
+
+	ch1 := pool.reserve().spawn(context.Background(), func() error {
+		<-block
+		return nil
+	})
+
+	Eventually(func() int { return collector.count(EventTypeSemaphoreAcquired) }).Should(Equal(1))
+
+	ch2 := pool.reserve().spawn(ctx, func() error {
+		called = true
+		return nil
+	})
+
+	// The caller stops waiting while the call is queued
+	cancel()
+	close(block)
+
+	Eventually(ch1).Should(Receive(BeNil()))
+	Eventually(ch2).Should(Receive(Equal(context.Canceled)))
+	Expect(called).To(BeFalse())
+	Expect(collector.count(EventTypeSemaphoreAcquired)).To(Equal(1))
+	Expect(collector.count(EventTypePoolTaskCompleted)).To(Equal(1))
+
+	// The skipped call no longer holds a place in the pool
+	Expect(pool.reserve()).NotTo(BeNil())
+	Expect(pool.reserve()).NotTo(BeNil())
+}
+
+func (s *PoolSuite) TestClose(t sweet.T) {
+	var (
+		pool  = newWorkerPool(2, 1, 0, newTestCollector())
+		block = make(chan struct{})
+		slot  = pool.reserve()
+	)
+
+	ch := pool.reserve().spawn(context.Background(), func() error {
+		<-block
+		return nil
+	})
+
+	pool.close()
+	Expect(pool.reserve()).To(BeNil())
+
+	// Calls which reserved a slot before the pool closed still run
+	Expect(<-slot.spawn(context.Background(), func() error { return testErr })).To(Equal(testErr))
+	close(block)
+	Eventually(ch).Should(Receive(BeNil()))
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		pool.workers.Wait()
+	}()
+
+	Eventually(stopped).Should(BeClosed())
+}
+
+func (s *PoolSuite) TestCloseReleased(t sweet.T) {
+	var (
+		pool = newWorkerPool(1, 0, 0, newTestCollector())
+		slot = pool.reserve()
+	)
+
+	pool.close()
+	slot.release()
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		pool.workers.Wait()
+	}()
+
+	Eventually(stopped).Should(BeClosed())
+}
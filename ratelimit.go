@@ -0,0 +1,91 @@
+package overcurrent
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/efritz/glock"
+)
+
+// tokenBucket allows calls at a steady rate with bursts of up to a fixed size.
+// The bucket holds up to burst tokens and is refilled at rate tokens per second.
+// Callers which are willing to wait reserve a future token, so the number of
+// tokens may become negative.
+type tokenBucket struct {
+	mutex  sync.Mutex
+	clock  glock.Clock
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(clock glock.Clock, rate float64, burst int) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &tokenBucket{
+		clock:  clock,
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   clock.Now(),
+	}
+}
+
+// wait takes a token from the bucket. If no token is available, it waits until
+// one becomes available. Returns false without waiting if a token would not be
+// available within the timeout, or if the context is canceled while waiting.
+func (b *tokenBucket) wait(ctx context.Context, timeout time.Duration) bool {
+	delay, ok := b.reserve(timeout)
+	if !ok {
+		return false
+	}
+
+	if delay <= 0 {
+		return true
+	}
+
+	select {
+	case <-b.clock.After(delay):
+		return true
+
+	case <-ctx.Done():
+		b.cancel()
+		return false
+	}
+}
+
+// reserve takes a token from the bucket and returns the time until the token
+// may be used. No token is taken if the delay would exceed the given timeout.
+func (b *tokenBucket) reserve(timeout time.Duration) (time.Duration, bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	now := b.clock.Now()
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+
+	var delay time.Duration
+	if b.tokens < 1 {
+		delay = time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+	}
+
+	if delay > timeout {
+		return 0, false
+	}
+
+	b.tokens--
+	return delay, true
+}
+
+// cancel returns a reserved token to the bucket.
+func (b *tokenBucket) cancel() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.tokens = math.Min(b.burst, b.tokens+1)
+}
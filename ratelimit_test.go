@@ -0,0 +1,85 @@
+package overcurrent
+
+import (
+	"context"
+	"time"
+
+	"github.com/aphistic/sweet"
+	"github.com/efritz/glock"
+	. "github.com/onsi/gomega"
+)
+
+type RateLimitSuite struct{}
+
+func (s *RateLimitSuite) TestBurst(t sweet.T) {
+	var (
+		clock  = glock.NewMockClock()
+		bucket = newTokenBucket(clock, 2, 3)
+	)
+
+	for i := 0; i < 3; i++ {
+		Expect(bucket.wait(context.Background(), 0)).To(BeTrue())
+	}
+
+	Expect(bucket.wait(context.Background(), 0)).To(BeFalse())
+
+	clock.Advance(500 * time.Millisecond)
+	Expect(bucket.wait(context.Background(), 0)).To(BeTrue())
+	Expect(bucket.wait(context.Background(), 0)).To(BeFalse())
+
+	// Does not accumulate more than burst tokens
+	clock.Advance(time.Minute)
+
+	for i := 0; i < 3; i++ {
+		Expect(bucket.wait(context.Background(), 0)).To(BeTrue())
+	}
+
+	Expect(bucket.wait(context.Background(), 0)).To(BeFalse())
+}
+
+func (s *RateLimitSuite) TestWait(t sweet.T) {
+	var (
+		clock  = glock.NewMockClock()
+		bucket = newTokenBucket(clock, 1, 1)
+		value  = make(chan bool)
+	)
+
+	Expect(bucket.wait(context.Background(), 0)).To(BeTrue())
+
+	// Next token is not available within the timeout
+	Expect(bucket.wait(context.Background(), 500*time.Millisecond)).To(BeFalse())
+
+	go func() {
+		defer close(value)
+		value <- bucket.wait(context.Background(), time.Second)
+	}()
+
+	Consistently(value).ShouldNot(Receive())
+	clock.BlockingAdvance(time.Second)
+	Eventually(value).Should(Receive(BeTrue()))
+	Expect(bucket.wait(context.Background(), 0)).To(BeFalse())
+}
+
+func (s *RateLimitSuite) TestWaitCancel(t sweet.T) {
+	var (
+		clock       = glock.NewMockClock()
+		bucket      = newTokenBucket(clock, 1, 1)
+		value       = make(chan bool)
+		ctx, cancel = context.WithCancel(context.Background())
+	)
+
+	Expect(bucket.wait(ctx, 0)).To(BeTrue())
+
+	go func() {
+		defer close(value)
+		value <- bucket.wait(ctx, time.Second)
+	}()
+
+	Consistently(value).ShouldNot(Receive())
+	cancel()
+	Eventually(value).Should(Receive(BeFalse()))
+
+	// The reserved token is returned
+	clock.Advance(time.Second)
+	Expect(bucket.wait(context.Background(), 0)).To(BeTrue())
+}
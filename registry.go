@@ -1,10 +1,14 @@
 package overcurrent
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"sort"
 	"sync"
 	"time"
 
+	"github.com/efritz/backoff"
 	"github.com/efritz/glock"
 )
 
@@ -22,20 +26,59 @@ type (
 		// invoked without the breaker function failing (e.g. circuit open).
 		Call(name string, f BreakerFunc, fallback FallbackFunc) error
 
+		// CallContext behaves like Call, but the context passed to the breaker function
+		// is derived from the given context. Cancellation of the given context will also
+		// stop waiting for a semaphore token or for the rate limit. If the call fails
+		// because the context was canceled, the failure is not counted against the breaker
+		// and the fallback is not invoked. If the deadline of the given context elapses
+		// during the call, the call counts as a timeout.
+		CallContext(ctx context.Context, name string, f BreakerFunc, fallback FallbackFunc) error
+
+		// CallWithFallback behaves like CallContext, but the fallback function also
+		// receives the given context, the name of the breaker, and the reason the
+		// fallback was invoked. Use FallbackChain to try several fallbacks in order.
+		CallWithFallback(ctx context.Context, name string, f BreakerFunc, fallback FallbackContextFunc) error
+
 		// CallAsync will create a channel that receives the error value from an similar
 		// invocation of Call. See the Breaker docs for more details.
 		CallAsync(name string, f BreakerFunc, fallback FallbackFunc) <-chan error
+
+		// OnStateChange registers a listener which is invoked each time any breaker in
+		// the registry changes state. The name of the breaker is included in the state
+		// change value.
+		OnStateChange(listener StateChangeListener)
+
+		// Names returns the sorted names of all configured breakers.
+		Names() []string
+
+		// State returns the current state of the breaker configured with the given
+		// name. This method does not cause any state transitions.
+		State(name string) (CircuitState, error)
+
+		// Snapshot returns a point-in-time view of the breaker configured with the
+		// given name. This method does not cause any state transitions.
+		Snapshot(name string) (Snapshot, error)
+
+		// Close closes every breaker in the registry. See the Close method of the
+		// CircuitBreaker interface for details.
+		Close() error
 	}
 
 	registry struct {
-		breakers map[string]*wrappedBreaker
-		mutex    sync.RWMutex
-		clock    glock.Clock
+		breakers    map[string]*wrappedBreaker
+		defaults    []func() []BreakerConfigFunc
+		defaultsErr error
+		listeners   []StateChangeListener
+		mutex       sync.RWMutex
+		clock       glock.Clock
 	}
 
+	RegistryConfigFunc func(*registry)
+
 	wrappedBreaker struct {
-		breaker   *circuitBreaker
-		semaphore *semaphore
+		breaker           *circuitBreaker
+		semaphore         *semaphore
+		fallbackSemaphore *semaphore
 	}
 
 	FallbackFunc func(error) error
@@ -45,45 +88,136 @@ var (
 	ErrAlreadyConfigured   = errors.New("breaker is already configured")
 	ErrBreakerUnconfigured = errors.New("breaker not configured")
 	ErrMaxConcurrency      = errors.New("breaker is at max concurrency")
+	ErrRateLimited         = errors.New("breaker is rate limited")
+	ErrFallbackRejected    = errors.New("fallback is at max concurrency")
+	ErrFallbackTimeout     = fmt.Errorf("fallback has timed out: %w", context.DeadlineExceeded)
+	ErrStatefulDefaults    = errors.New("breaker defaults share a trip condition, reset backoff, or concurrency limiter")
 )
 
-func NewRegistry() Registry {
-	return newRegistryWithClock(glock.NewRealClock())
+func NewRegistry(configs ...RegistryConfigFunc) Registry {
+	return newRegistryWithClock(glock.NewRealClock(), configs...)
 }
 
-func newRegistryWithClock(clock glock.Clock) Registry {
-	return &registry{
+func newRegistryWithClock(clock glock.Clock, configs ...RegistryConfigFunc) Registry {
+	r := &registry{
 		breakers: map[string]*wrappedBreaker{},
 		clock:    clock,
 	}
+
+	for _, config := range configs {
+		config(r)
+	}
+
+	return r
+}
+
+// WithBreakerDefaults applies the given configuration to every breaker configured in
+// the registry. The configuration given to Configure is applied after the defaults.
+// For example, WithBreakerDefaults(WithDryRun()) puts every breaker into dry-run mode.
+//
+// The same options are applied to every breaker, so they must not hold per-breaker
+// state. Options which set a trip condition, reset backoff, or concurrency limiter
+// cause Configure to fail with ErrStatefulDefaults; use WithBreakerDefaultsFunc for
+// these instead. A retry budget is meant to be shared and may be given here.
+func WithBreakerDefaults(configs ...BreakerConfigFunc) RegistryConfigFunc {
+	return func(r *registry) {
+		if sharesState(configs) {
+			r.defaultsErr = ErrStatefulDefaults
+			return
+		}
+
+		r.defaults = append(r.defaults, func() []BreakerConfigFunc { return configs })
+	}
+}
+
+// WithBreakerDefaultsFunc behaves like WithBreakerDefaults, but the given function is
+// invoked once for each breaker configured in the registry so that stateful options
+// (such as a trip condition) can be created for each breaker.
+func WithBreakerDefaultsFunc(defaults func() []BreakerConfigFunc) RegistryConfigFunc {
+	return func(r *registry) { r.defaults = append(r.defaults, defaults) }
+}
+
+// sharesState returns true if the given options set a trip condition, reset backoff,
+// or concurrency limiter, which would be shared by every breaker given the options.
+func sharesState(configs []BreakerConfigFunc) bool {
+	var (
+		tripCondition = NewConsecutiveFailureTripCondition(5)
+		resetBackoff  = backoff.NewConstantBackoff(time.Second)
+		breaker       = &circuitBreaker{tripCondition: tripCondition, resetBackoff: resetBackoff}
+	)
+
+	for _, config := range configs {
+		config(breaker)
+	}
+
+	return breaker.tripCondition != tripCondition || breaker.resetBackoff != resetBackoff || breaker.concurrencyLimiter != nil
 }
 
 func (r *registry) Configure(name string, configs ...BreakerConfigFunc) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
+	if r.defaultsErr != nil {
+		return r.defaultsErr
+	}
+
 	if _, ok := r.breakers[name]; ok {
 		return ErrAlreadyConfigured
 	}
 
-	breaker := newCircuitBreaker(configs...)
+	defaults := []BreakerConfigFunc{}
+	for _, f := range r.defaults {
+		defaults = append(defaults, f()...)
+	}
+
+	configs = append(
+		append(defaults, configs...),
+		WithName(name),
+		WithStateChangeListener(r.notifyStateChange),
+	)
+
+	var (
+		breaker = newCircuitBreaker(configs...)
+		limiter = breaker.concurrencyLimiter
+	)
+
+	if limiter == nil {
+		limiter = NewFixedConcurrencyLimiter(breaker.maxConcurrency)
+	}
+
+	if breaker.pool == nil {
+		reportGauge(breaker.collector, EventTypeConcurrencyLimit, float64(limiter.Limit()))
+	}
 
-	r.breakers[name] = &wrappedBreaker{
+	wrapped := &wrappedBreaker{
 		breaker:   breaker,
-		semaphore: newSemaphore(r.clock, breaker.maxConcurrency),
+		semaphore: newLimitedSemaphore(r.clock, limiter),
 	}
 
+	if breaker.fallbackMaxConcurrency > 0 {
+		wrapped.fallbackSemaphore = newSemaphore(r.clock, breaker.fallbackMaxConcurrency)
+	}
+
+	r.breakers[name] = wrapped
 	return nil
 }
 
 func (r *registry) Call(name string, f BreakerFunc, fallback FallbackFunc) error {
+	return r.CallContext(context.Background(), name, f, fallback)
+}
+
+func (r *registry) CallContext(ctx context.Context, name string, f BreakerFunc, fallback FallbackFunc) error {
+	return r.CallWithFallback(ctx, name, f, fallback.withContext())
+}
+
+func (r *registry) CallWithFallback(ctx context.Context, name string, f BreakerFunc, fallback FallbackContextFunc) error {
 	wrapped, collector, err := r.getWrappedBreaker(name)
 	if err != nil {
 		return err
 	}
 
 	start := time.Now()
-	err = r.call(wrapped, collector, f, fallback)
+	err = r.call(ctx, wrapped, collector, f, fallback)
 	elapsed := time.Now().Sub(start)
 
 	collector.ReportDuration(EventTypeTotalDuration, elapsed)
@@ -96,6 +230,65 @@ func (r *registry) CallAsync(name string, f BreakerFunc, fallback FallbackFunc)
 	})
 }
 
+func (r *registry) OnStateChange(listener StateChangeListener) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.listeners = append(r.listeners, listener)
+}
+
+func (r *registry) notifyStateChange(change StateChange) {
+	r.mutex.RLock()
+	listeners := r.listeners
+	r.mutex.RUnlock()
+
+	for _, listener := range listeners {
+		listener(change)
+	}
+}
+
+func (r *registry) Names() []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	names := []string{}
+	for name := range r.breakers {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+func (r *registry) State(name string) (CircuitState, error) {
+	wrapped, _, err := r.getWrappedBreaker(name)
+	if err != nil {
+		return 0, err
+	}
+
+	return wrapped.breaker.State(), nil
+}
+
+func (r *registry) Snapshot(name string) (Snapshot, error) {
+	wrapped, _, err := r.getWrappedBreaker(name)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	return wrapped.breaker.Snapshot(), nil
+}
+
+func (r *registry) Close() error {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for _, wrapped := range r.breakers {
+		wrapped.breaker.Close()
+	}
+
+	return nil
+}
+
 func (r *registry) getWrappedBreaker(name string) (*wrappedBreaker, MetricCollector, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
@@ -108,26 +301,28 @@ func (r *registry) getWrappedBreaker(name string) (*wrappedBreaker, MetricCollec
 	return wrapped, wrapped.breaker.collector, nil
 }
 
-func (r *registry) call(wrapped *wrappedBreaker, collector MetricCollector, f BreakerFunc, fallback FallbackFunc) error {
+func (r *registry) call(ctx context.Context, wrapped *wrappedBreaker, collector MetricCollector, f BreakerFunc, fallback FallbackContextFunc) error {
 	collector.ReportCount(EventTypeAttempt)
 
-	err := r.callWithSemaphore(wrapped.breaker, wrapped.semaphore, f)
+	err := wrapped.breaker.withRetries(ctx, func() error {
+		return r.callWithHedging(ctx, wrapped, f)
+	})
 	if err == nil {
 		collector.ReportCount(EventTypeSuccess)
 		return nil
 	}
 
-	collector.ReportCount(EventTypeFailure)
-
-	if err == ErrMaxConcurrency {
-		collector.ReportCount(EventTypeRejection)
+	if isCallerError(ctx, err) {
+		return err
 	}
 
+	collector.ReportCount(EventTypeFailure)
+
 	if fallback == nil {
 		return err
 	}
 
-	if err := fallback(err); err != nil {
+	if err := r.callFallback(ctx, wrapped, fallback, err); err != nil {
 		collector.ReportCount(EventTypeFallbackFailure)
 		return err
 	}
@@ -136,9 +331,66 @@ func (r *registry) call(wrapped *wrappedBreaker, collector MetricCollector, f Br
 	return nil
 }
 
-func (r *registry) callWithSemaphore(breaker *circuitBreaker, semaphore *semaphore, f BreakerFunc) error {
-	if !semaphore.wait(breaker.maxConcurrencyTimeout, breaker.collector) {
-		return ErrMaxConcurrency
+// callFallback invokes the fallback function with the error of the breaker func,
+// subject to the fallback timeout and max concurrency of the breaker.
+func (r *registry) callFallback(ctx context.Context, wrapped *wrappedBreaker, fallback FallbackContextFunc, err error) error {
+	breaker := wrapped.breaker
+
+	if wrapped.fallbackSemaphore != nil && !wrapped.fallbackSemaphore.wait(ctx, 0, breaker.collector) {
+		breaker.collector.ReportCount(EventTypeFallbackRejection)
+		return ErrFallbackRejected
+	}
+
+	reason := fallbackReason(err)
+
+	f := func(ctx context.Context) error {
+		if wrapped.fallbackSemaphore != nil {
+			// Release once the fallback returns, even if it timed out
+			defer wrapped.fallbackSemaphore.signal()
+		}
+
+		return fallback(ctx, breaker.name, reason, err)
+	}
+
+	// The caller's deadline may already have passed (which is why the fallback
+	// is invoked), so the fallback is bounded only by the fallback timeout.
+	start := breaker.clock.Now()
+	fallbackErr := callWithTimeout(withoutCancel(ctx), f, breaker.clock, breaker.fallbackTimeout)
+	breaker.collector.ReportDuration(EventTypeFallbackDuration, breaker.clock.Now().Sub(start))
+
+	if fallbackErr == ErrInvocationTimeout {
+		return ErrFallbackTimeout
+	}
+
+	return fallbackErr
+}
+
+func (r *registry) callWithSemaphore(ctx context.Context, breaker *circuitBreaker, semaphore *semaphore, f BreakerFunc) error {
+	if breaker.rateLimiter != nil && !breaker.rateLimiter.wait(ctx, breaker.rateLimitTimeout) {
+		// An elapsed deadline is reported like any other rate limited call
+		if err := ctx.Err(); errors.Is(err, context.Canceled) {
+			breaker.collector.ReportCount(EventTypeCancelled)
+			return err
+		}
+
+		breaker.collector.ReportCount(EventTypeRateLimited)
+		return &RateLimitError{Name: breaker.name, Rate: breaker.rateLimit}
+	}
+
+	if breaker.pool != nil {
+		// The breaker's pool bounds concurrency instead
+		return breaker.callContext(ctx, f)
+	}
+
+	if !semaphore.wait(ctx, breaker.maxConcurrencyTimeout, breaker.collector) {
+		// An elapsed deadline is reported like any other rejection
+		if err := ctx.Err(); errors.Is(err, context.Canceled) {
+			breaker.collector.ReportCount(EventTypeCancelled)
+			return err
+		}
+
+		breaker.collector.ReportCount(EventTypeRejection)
+		return &MaxConcurrencyError{Name: breaker.name, MaxConcurrency: semaphore.limiter.Limit()}
 	}
 
 	defer func() {
@@ -147,5 +399,22 @@ func (r *registry) callWithSemaphore(breaker *circuitBreaker, semaphore *semapho
 	}()
 
 	breaker.collector.ReportCount(EventTypeSemaphoreAcquired)
-	return breaker.Call(f)
+
+	var (
+		inFlight = semaphore.inFlight()
+		start    = breaker.clock.Now()
+		err      = breaker.callContext(ctx, f)
+	)
+
+	// Calls which were short-circuited or canceled by the caller say nothing
+	// about the concurrency the dependency can sustain
+	if !errors.Is(err, ErrCircuitOpen) && !errors.Is(err, ErrMaxAbandoned) && !isCallerError(ctx, err) {
+		elapsed := breaker.clock.Now().Sub(start)
+
+		if limit, changed := semaphore.observe(elapsed, inFlight, errors.Is(err, ErrInvocationTimeout)); changed {
+			reportGauge(breaker.collector, EventTypeConcurrencyLimit, float64(limit))
+		}
+	}
+
+	return err
 }
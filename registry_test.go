@@ -3,9 +3,12 @@ package overcurrent
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/efritz/backoff"
 	"github.com/efritz/glock"
 
 	"github.com/aphistic/sweet"
@@ -31,6 +34,24 @@ func (s *RegistrySuite) TestSuccessfulCall(t sweet.T) {
 	Expect(called).To(BeTrue())
 }
 
+func (s *RegistrySuite) TestCallContext(t sweet.T) {
+	var (
+		r     = NewRegistry()
+		ctx   = context.WithValue(context.Background(), "key", "value")
+		value interface{}
+	)
+
+	r.Configure("test")
+
+	err := r.CallContext(ctx, "test", func(ctx context.Context) error {
+		value = ctx.Value("key")
+		return nil
+	}, nil)
+
+	Expect(err).To(BeNil())
+	Expect(value).To(Equal("value"))
+}
+
 func (s *RegistrySuite) TestErrorCall(t sweet.T) {
 	var (
 		r  = NewRegistry()
@@ -67,6 +88,24 @@ func (s *RegistrySuite) TestErrorCallWithFallback(t sweet.T) {
 	Expect(called).To(BeTrue())
 }
 
+func (s *RegistrySuite) TestPanicWithFallback(t sweet.T) {
+	var (
+		r      = NewRegistry()
+		called = false
+	)
+
+	r.Configure("test")
+
+	err := r.Call("test", panicFunc, func(err error) error {
+		Expect(err).To(BeAssignableToTypeOf(&PanicError{}))
+		called = true
+		return nil
+	})
+
+	Expect(err).To(BeNil())
+	Expect(called).To(BeTrue())
+}
+
 func (s *RegistrySuite) TestFallbackError(t sweet.T) {
 	var (
 		r    = NewRegistry()
@@ -86,6 +125,175 @@ func (s *RegistrySuite) TestFallbackError(t sweet.T) {
 	Expect(err).To(Equal(err2))
 }
 
+func (s *RegistrySuite) TestCallWithFallback(t sweet.T) {
+	type contextKey struct{}
+
+	var (
+		r       = NewRegistry()
+		ctx     = context.WithValue(context.Background(), contextKey{}, "value")
+		reasons = make(chan FallbackReason, 1)
+	)
+
+	r.Configure(
+		"test",
+		testConfig(),
+		WithInvocationTimeout(time.Millisecond),
+		WithRateLimit(0.001, 3),
+	)
+
+	fallback := func(ctx context.Context, name string, reason FallbackReason, err error) error {
+		Expect(ctx.Value(contextKey{})).To(Equal("value"))
+		Expect(name).To(Equal("test"))
+		reasons <- reason
+		return nil
+	}
+
+	Expect(r.CallWithFallback(ctx, "test", errFunc, fallback)).To(BeNil())
+	Expect(reasons).To(Receive(Equal(FallbackReasonError)))
+
+	Expect(r.CallWithFallback(ctx, "test", blockingFunc, fallback)).To(BeNil())
+	Expect(reasons).To(Receive(Equal(FallbackReasonTimeout)))
+
+	Expect(r.CallWithFallback(ctx, "test", nilFunc, fallback)).To(BeNil())
+	Expect(reasons).NotTo(Receive())
+
+	// Burst is exhausted
+	Expect(r.CallWithFallback(ctx, "test", nilFunc, fallback)).To(BeNil())
+	Expect(reasons).To(Receive(Equal(FallbackReasonRejection)))
+
+	wrapped, _, _ := r.(*registry).getWrappedBreaker("test")
+	wrapped.breaker.Trip()
+	wrapped.breaker.rateLimiter = nil
+
+	Expect(r.CallWithFallback(ctx, "test", nilFunc, fallback)).To(BeNil())
+	Expect(reasons).To(Receive(Equal(FallbackReasonCircuitOpen)))
+}
+
+func (s *RegistrySuite) TestFallbackTimeout(t sweet.T) {
+	var (
+		r         = NewRegistry()
+		clock     = glock.NewMockClock()
+		collector = newTestCollector()
+		errors    = make(chan error)
+	)
+
+	r.Configure(
+		"test",
+		testConfig(),
+		WithInvocationTimeout(0),
+		WithCollector(collector),
+		WithFallbackTimeout(time.Second),
+		withClock(clock),
+	)
+
+	go func() {
+		defer close(errors)
+
+		errors <- r.CallWithFallback(context.Background(), "test", errFunc, func(ctx context.Context, name string, reason FallbackReason, err error) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+	}()
+
+	clock.BlockingAdvance(time.Second)
+	Eventually(errors).Should(Receive(Equal(ErrFallbackTimeout)))
+	Expect(collector.count(EventTypeFallbackFailure)).To(Equal(1))
+	Expect(collector.durations(EventTypeFallbackDuration)).To(Equal([]time.Duration{time.Second}))
+}
+
+func (s *RegistrySuite) TestFallbackMaxConcurrency(t sweet.T) {
+	var (
+		r         = NewRegistry()
+		collector = newTestCollector()
+		block     = make(chan struct{})
+		started   = make(chan struct{}, 1)
+		errors    = make(chan error, 1)
+	)
+
+	r.Configure(
+		"test",
+		testConfig(),
+		WithCollector(collector),
+		WithFallbackMaxConcurrency(1),
+	)
+
+	go func() {
+		errors <- r.Call("test", errFunc, func(err error) error {
+			started <- struct{}{}
+			<-block
+			return nil
+		})
+	}()
+
+	Eventually(started).Should(Receive())
+
+	Expect(r.Call("test", errFunc, func(err error) error {
+		return nil
+	})).To(Equal(ErrFallbackRejected))
+
+	Expect(collector.count(EventTypeFallbackRejection)).To(Equal(1))
+	Expect(collector.count(EventTypeFallbackFailure)).To(Equal(1))
+
+	close(block)
+	Eventually(errors).Should(Receive(BeNil()))
+	Expect(r.Call("test", errFunc, func(err error) error { return nil })).To(BeNil())
+	Expect(collector.count(EventTypeFallbackSuccess)).To(Equal(2))
+	Expect(collector.configs[0].FallbackMaxConcurrency).To(Equal(1))
+}
+
+func (s *RegistrySuite) TestFallbackAfterCallerDeadline(t sweet.T) {
+	r := NewRegistry()
+	r.Configure("test", testConfig(), WithTripCondition(NewConsecutiveFailureTripCondition(100)))
+
+	type key struct{}
+
+	waitFunc := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	for i := 0; i < 20; i++ {
+		ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), key{}, "value"), 5*time.Millisecond)
+
+		err := r.CallWithFallback(ctx, "test", waitFunc, func(ctx context.Context, name string, reason FallbackReason, err error) error {
+			// The fallback does not observe the expired deadline
+			Expect(ctx.Err()).To(BeNil())
+			Expect(ctx.Value(key{})).To(Equal("value"))
+			Expect(reason).To(Equal(FallbackReasonTimeout))
+			return nil
+		})
+
+		cancel()
+		Expect(err).To(BeNil())
+	}
+}
+
+func (s *RegistrySuite) TestCancelledCallSkipsFallback(t sweet.T) {
+	var (
+		r           = NewRegistry()
+		called      = false
+		ctx, cancel = context.WithCancel(context.Background())
+	)
+
+	r.Configure("test", testConfig())
+	cancel()
+
+	for i := 0; i < 10; i++ {
+		err := r.CallContext(ctx, "test", func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}, func(err error) error {
+			called = true
+			return nil
+		})
+
+		Expect(err).To(Equal(context.Canceled))
+	}
+
+	Expect(called).To(BeFalse())
+	Expect(r.Call("test", errFunc, nil)).To(Equal(testErr))
+}
+
 func (s *RegistrySuite) TestBreaker(t sweet.T) {
 	var (
 		r         = NewRegistry()
@@ -103,11 +311,373 @@ func (s *RegistrySuite) TestBreaker(t sweet.T) {
 		Expect(r.Call("test", errFunc, fallback)).To(BeNil())
 	}
 
-	Expect(r.Call("test", errFunc, nil)).To(Equal(ErrCircuitOpen))
+	Expect(r.Call("test", errFunc, nil)).To(beError(ErrCircuitOpen))
 	Expect(r.Call("test", errFunc, fallback)).To(BeNil())
 	Expect(callCount).To(Equal(6))
 }
 
+func (s *RegistrySuite) TestBreakerDefaults(t sweet.T) {
+	var (
+		r         = NewRegistry(WithBreakerDefaults(WithDryRun()))
+		callCount = 0
+	)
+
+	r.Configure("test", testConfig())
+
+	fallback := func(err error) error {
+		callCount++
+		return nil
+	}
+
+	for i := 0; i < 5; i++ {
+		Expect(r.Call("test", errFunc, fallback)).To(BeNil())
+	}
+
+	Expect(r.Call("test", nilFunc, fallback)).To(BeNil())
+	Expect(r.Call("test", errFunc, nil)).To(Equal(testErr))
+	Expect(r.State("test")).To(Equal(StateOpen))
+	Expect(callCount).To(Equal(5))
+}
+
+func (s *RegistrySuite) TestBreakerDefaultsStateful(t sweet.T) {
+	r := NewRegistry(WithBreakerDefaults(
+		WithDryRun(),
+		WithTripCondition(NewConsecutiveFailureTripCondition(2)),
+	))
+
+	Expect(r.Configure("test")).To(Equal(ErrStatefulDefaults))
+
+	for _, config := range []BreakerConfigFunc{
+		WithResetBackoff(backoff.NewConstantBackoff(time.Second)),
+		WithConcurrencyLimiter(NewFixedConcurrencyLimiter(1)),
+	} {
+		Expect(NewRegistry(WithBreakerDefaults(config)).Configure("test")).To(Equal(ErrStatefulDefaults))
+	}
+
+	// A shared retry budget is allowed
+	r = NewRegistry(WithBreakerDefaults(WithRetryBudget(NewRetryBudget(0.1, 1, time.Second))))
+	Expect(r.Configure("test")).To(BeNil())
+}
+
+func (s *RegistrySuite) TestBreakerDefaultsFunc(t sweet.T) {
+	r := NewRegistry(WithBreakerDefaultsFunc(func() []BreakerConfigFunc {
+		return []BreakerConfigFunc{
+			WithTripCondition(NewConsecutiveFailureTripCondition(2)),
+		}
+	}))
+
+	Expect(r.Configure("a")).To(BeNil())
+	Expect(r.Configure("b")).To(BeNil())
+
+	Expect(r.Call("a", errFunc, nil)).To(Equal(testErr))
+	Expect(r.Call("a", errFunc, nil)).To(Equal(testErr))
+	Expect(r.Call("a", nilFunc, nil)).To(beError(ErrCircuitOpen))
+
+	// Each breaker has its own trip condition
+	Expect(r.Call("b", nilFunc, nil)).To(BeNil())
+	Expect(r.State("b")).To(Equal(StateClosed))
+}
+
+func (s *RegistrySuite) TestRetry(t sweet.T) {
+	var (
+		r         = NewRegistry()
+		collector = newTestCollector()
+		attempts  = 0
+		fallbacks = 0
+	)
+
+	r.Configure(
+		"test",
+		testConfig(),
+		WithCollector(collector),
+		WithMaxConcurrency(1),
+		WithRetry(backoff.NewConstantBackoff(time.Millisecond), 3, nil),
+	)
+
+	err := r.Call("test", func(ctx context.Context) error {
+		attempts++
+		return testErr
+	}, func(err error) error {
+		Expect(err).To(Equal(testErr))
+		fallbacks++
+		return nil
+	})
+
+	Expect(err).To(BeNil())
+	Expect(attempts).To(Equal(3))
+	Expect(fallbacks).To(Equal(1))
+	Expect(collector.count(EventTypeAttempt)).To(Equal(1))
+	Expect(collector.count(EventTypeRetry)).To(Equal(2))
+	Expect(collector.count(EventTypeSemaphoreAcquired)).To(Equal(3))
+	Expect(collector.count(EventTypeSemaphoreReleased)).To(Equal(3))
+}
+
+func (s *RegistrySuite) TestHedge(t sweet.T) {
+	var (
+		r         = NewRegistry()
+		clock     = glock.NewMockClock()
+		collector = newTestCollector()
+		calls     = int32(0)
+		errors    = make(chan error)
+	)
+
+	r.Configure(
+		"test",
+		testConfig(),
+		WithInvocationTimeout(0),
+		WithCollector(collector),
+		WithHedging(time.Second, nil),
+		withClock(clock),
+	)
+
+	go func() {
+		defer close(errors)
+
+		errors <- r.CallContext(AllowHedging(context.Background()), "test", func(ctx context.Context) error {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				<-ctx.Done()
+				return ctx.Err()
+			}
+
+			return nil
+		}, nil)
+	}()
+
+	clock.BlockingAdvance(time.Second)
+	Eventually(errors).Should(Receive(BeNil()))
+	Expect(atomic.LoadInt32(&calls)).To(Equal(int32(2)))
+	Expect(collector.count(EventTypeHedge)).To(Equal(1))
+	Expect(collector.count(EventTypeSuccess)).To(Equal(1))
+	Eventually(func() int { return collector.count(EventTypeCancelled) }).Should(Equal(1))
+}
+
+func (s *RegistrySuite) TestHedgeNotAllowed(t sweet.T) {
+	var (
+		r         = NewRegistry()
+		clock     = glock.NewMockClock()
+		collector = newTestCollector()
+		block     = make(chan struct{})
+		calls     = int32(0)
+		errors    = make(chan error)
+	)
+
+	r.Configure(
+		"test",
+		testConfig(),
+		WithInvocationTimeout(0),
+		WithCollector(collector),
+		WithHedging(time.Second, nil),
+		withClock(clock),
+	)
+
+	go func() {
+		defer close(errors)
+
+		errors <- r.Call("test", func(ctx context.Context) error {
+			atomic.AddInt32(&calls, 1)
+			<-block
+			return nil
+		}, nil)
+	}()
+
+	clock.Advance(time.Minute)
+	Consistently(errors).ShouldNot(Receive())
+	close(block)
+	Eventually(errors).Should(Receive(BeNil()))
+	Expect(atomic.LoadInt32(&calls)).To(Equal(int32(1)))
+	Expect(collector.count(EventTypeHedge)).To(Equal(0))
+}
+
+func (s *RegistrySuite) TestHedgeNotNeeded(t sweet.T) {
+	var (
+		r         = NewRegistry()
+		collector = newTestCollector()
+	)
+
+	r.Configure(
+		"test",
+		testConfig(),
+		WithCollector(collector),
+		WithHedging(time.Minute, nil),
+	)
+
+	Expect(r.CallContext(AllowHedging(context.Background()), "test", nilFunc, nil)).To(BeNil())
+	Expect(r.CallContext(AllowHedging(context.Background()), "test", errFunc, nil)).To(Equal(testErr))
+	Expect(collector.count(EventTypeHedge)).To(Equal(0))
+}
+
+func (s *RegistrySuite) TestHedgeBothFail(t sweet.T) {
+	var (
+		r      = NewRegistry()
+		clock  = glock.NewMockClock()
+		block  = make(chan struct{})
+		calls  = int32(0)
+		errors = make(chan error)
+	)
+
+	r.Configure(
+		"test",
+		testConfig(),
+		WithInvocationTimeout(0),
+		WithHedging(time.Second, nil),
+		withClock(clock),
+	)
+
+	go func() {
+		defer close(errors)
+
+		errors <- r.CallContext(AllowHedging(context.Background()), "test", func(ctx context.Context) error {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				<-block
+				return testErr
+			}
+
+			return fmt.Errorf("hedge error")
+		}, nil)
+	}()
+
+	clock.BlockingAdvance(time.Second)
+	Consistently(errors).ShouldNot(Receive())
+	close(block)
+	Eventually(errors).Should(Receive(Equal(testErr)))
+}
+
+func (s *RegistrySuite) TestHedgeBudget(t sweet.T) {
+	var (
+		r         = NewRegistry()
+		clock     = glock.NewMockClock()
+		collector = newTestCollector()
+		block     = make(chan struct{})
+		calls     = int32(0)
+		errors    = make(chan error)
+	)
+
+	r.Configure(
+		"test",
+		testConfig(),
+		WithInvocationTimeout(0),
+		WithCollector(collector),
+		WithHedging(time.Second, NewRetryBudget(0, 0, time.Minute)),
+		withClock(clock),
+	)
+
+	go func() {
+		defer close(errors)
+
+		errors <- r.CallContext(AllowHedging(context.Background()), "test", func(ctx context.Context) error {
+			atomic.AddInt32(&calls, 1)
+			<-block
+			return nil
+		}, nil)
+	}()
+
+	clock.BlockingAdvance(time.Second)
+	Consistently(errors).ShouldNot(Receive())
+	close(block)
+	Eventually(errors).Should(Receive(BeNil()))
+	Expect(atomic.LoadInt32(&calls)).To(Equal(int32(1)))
+	Expect(collector.count(EventTypeHedge)).To(Equal(0))
+}
+
+func (s *RegistrySuite) TestHedgeMaxConcurrency(t sweet.T) {
+	var (
+		r         = NewRegistry()
+		clock     = glock.NewMockClock()
+		collector = newTestCollector()
+		block     = make(chan struct{})
+		calls     = int32(0)
+		errors    = make(chan error)
+	)
+
+	r.Configure(
+		"test",
+		testConfig(),
+		WithInvocationTimeout(0),
+		WithCollector(collector),
+		WithMaxConcurrency(1),
+		WithMaxConcurrencyTimeout(0),
+		WithHedging(time.Second, nil),
+		withClock(clock),
+	)
+
+	go func() {
+		defer close(errors)
+
+		errors <- r.CallContext(AllowHedging(context.Background()), "test", func(ctx context.Context) error {
+			atomic.AddInt32(&calls, 1)
+			<-block
+			return nil
+		}, nil)
+	}()
+
+	clock.BlockingAdvance(time.Second)
+	Eventually(func() int { return collector.count(EventTypeHedge) }).Should(Equal(1))
+	close(block)
+	Eventually(errors).Should(Receive(BeNil()))
+	Expect(atomic.LoadInt32(&calls)).To(Equal(int32(1)))
+}
+
+func (s *RegistrySuite) TestAdaptiveHedgeDelay(t sweet.T) {
+	r := NewRegistry()
+	r.Configure(
+		"test",
+		testConfig(),
+		WithAdaptiveHedging(2, 1, time.Millisecond, time.Minute, nil),
+	)
+
+	breaker := r.(*registry).breakers["test"].breaker
+	Expect(breaker.hedging.currentDelay()).To(Equal(time.Minute))
+
+	Expect(r.Call("test", nilFunc, nil)).To(BeNil())
+	Expect(r.Call("test", nilFunc, nil)).To(BeNil())
+	Expect(breaker.hedging.currentDelay()).To(Equal(time.Millisecond))
+}
+
+func (s *RegistrySuite) TestThreadPool(t sweet.T) {
+	var (
+		r       = NewRegistry()
+		block   = make(chan struct{})
+		started = make(chan struct{}, 2)
+		errors  = make(chan error, 2)
+	)
+
+	r.Configure(
+		"test",
+		testConfig(),
+		WithMaxConcurrency(1),
+		WithMaxConcurrencyTimeout(0),
+		WithThreadPool(2, 0, 0),
+	)
+
+	blocking := func(ctx context.Context) error {
+		started <- struct{}{}
+		<-block
+		return nil
+	}
+
+	// The pool bounds concurrency instead of the semaphore
+	go func() { errors <- r.Call("test", blocking, nil) }()
+	go func() { errors <- r.Call("test", blocking, nil) }()
+	Eventually(started).Should(Receive())
+	Eventually(started).Should(Receive())
+
+	Expect(r.Call("test", nilFunc, nil)).To(beError(ErrMaxConcurrency))
+
+	close(block)
+	Eventually(errors).Should(Receive(BeNil()))
+	Eventually(errors).Should(Receive(BeNil()))
+}
+
+func (s *RegistrySuite) TestClose(t sweet.T) {
+	r := NewRegistry()
+	r.Configure("test", testConfig(), WithThreadPool(2, 0, 0))
+	r.Configure("no-pool", testConfig())
+
+	Expect(r.Close()).To(BeNil())
+	Expect(r.Call("test", nilFunc, nil)).To(beError(ErrMaxConcurrency))
+	Expect(r.Call("no-pool", nilFunc, nil)).To(BeNil())
+}
+
 func (s *RegistrySuite) TestConcurrency(t sweet.T) {
 	var (
 		r       = NewRegistry()
@@ -145,7 +715,8 @@ func (s *RegistrySuite) TestConcurrency(t sweet.T) {
 	}
 
 	Expect(r.Call("test", nilFunc, func(err error) error {
-		Expect(err).To(Equal(ErrMaxConcurrency))
+		Expect(err).To(beError(ErrMaxConcurrency))
+		Expect(err).To(Equal(&MaxConcurrencyError{Name: "test", MaxConcurrency: 5}))
 		return nil
 	})).To(BeNil())
 
@@ -155,6 +726,135 @@ func (s *RegistrySuite) TestConcurrency(t sweet.T) {
 	Expect(r.Call("test", nilFunc, nil)).To(BeNil())
 }
 
+func (s *RegistrySuite) TestConcurrencyLimiter(t sweet.T) {
+	var (
+		r         = NewRegistry()
+		collector = newTestCollector()
+		block     = make(chan struct{})
+		started   = make(chan struct{}, 1)
+		errors    = make(chan error, 1)
+	)
+
+	r.Configure(
+		"test",
+		testConfig(),
+		WithCollector(collector),
+		WithMaxConcurrencyTimeout(0),
+		WithConcurrencyLimiter(NewAIMDConcurrencyLimiter(1, 1, 2, 0.5)),
+	)
+
+	Expect(collector.gauges(EventTypeConcurrencyLimit)).To(Equal([]float64{1}))
+
+	go func() {
+		errors <- r.Call("test", func(ctx context.Context) error {
+			started <- struct{}{}
+			<-block
+			return nil
+		}, nil)
+	}()
+
+	Eventually(started).Should(Receive())
+	Expect(r.Call("test", nilFunc, nil)).To(Equal(&MaxConcurrencyError{Name: "test", MaxConcurrency: 1}))
+
+	close(block)
+	Eventually(errors).Should(Receive(BeNil()))
+
+	// The limit grows after a call completes at the limit
+	Expect(collector.gauges(EventTypeConcurrencyLimit)).To(Equal([]float64{1, 2}))
+	Expect(r.Call("test", nilFunc, nil)).To(BeNil())
+	Expect(collector.gauges(EventTypeConcurrencyLimit)).To(Equal([]float64{1, 2}))
+}
+
+func (s *RegistrySuite) TestConcurrencyLimiterDropped(t sweet.T) {
+	var (
+		r         = NewRegistry()
+		collector = newTestCollector()
+	)
+
+	r.Configure(
+		"test",
+		testConfig(),
+		WithCollector(collector),
+		WithInvocationTimeout(time.Millisecond),
+		WithConcurrencyLimiter(NewAIMDConcurrencyLimiter(4, 1, 4, 0.5)),
+	)
+
+	Expect(r.Call("test", blockingFunc, nil)).To(beError(ErrInvocationTimeout))
+	Expect(collector.gauges(EventTypeConcurrencyLimit)).To(Equal([]float64{4, 2}))
+
+	// Short-circuited calls are not observed
+	r.Configure("open", testConfig(), WithCollector(collector), WithConcurrencyLimiter(NewAIMDConcurrencyLimiter(1, 1, 4, 0.5)))
+	wrapped, _, _ := r.(*registry).getWrappedBreaker("open")
+	wrapped.breaker.Trip()
+
+	Expect(r.Call("open", nilFunc, nil)).To(beError(ErrCircuitOpen))
+	Expect(collector.gauges(EventTypeConcurrencyLimit)).To(Equal([]float64{4, 2, 1}))
+}
+
+func (s *RegistrySuite) TestRateLimit(t sweet.T) {
+	var (
+		r         = NewRegistry()
+		clock     = glock.NewMockClock()
+		collector = newTestCollector()
+		fallback  = make(chan error, 1)
+	)
+
+	r.Configure(
+		"test",
+		testConfig(),
+		WithCollector(collector),
+		WithRateLimit(1, 2),
+		WithRetry(backoff.NewZeroBackoff(), 3, nil),
+		withClock(clock),
+	)
+
+	Expect(r.Call("test", nilFunc, nil)).To(BeNil())
+	Expect(r.Call("test", nilFunc, nil)).To(BeNil())
+
+	Expect(r.Call("test", nilFunc, func(err error) error {
+		fallback <- err
+		return nil
+	})).To(BeNil())
+
+	Expect(fallback).To(Receive(Equal(&RateLimitError{Name: "test", Rate: 1})))
+	Expect(collector.count(EventTypeRateLimited)).To(Equal(1))
+	Expect(collector.count(EventTypeFailure)).To(Equal(1))
+	Expect(collector.count(EventTypeFallbackSuccess)).To(Equal(1))
+	Expect(collector.count(EventTypeRetry)).To(Equal(0))
+
+	clock.Advance(time.Second)
+	Expect(r.Call("test", nilFunc, nil)).To(BeNil())
+	Expect(r.State("test")).To(Equal(StateClosed))
+}
+
+func (s *RegistrySuite) TestRateLimitTimeout(t sweet.T) {
+	var (
+		r      = NewRegistry()
+		clock  = glock.NewMockClock()
+		errors = make(chan error)
+	)
+
+	r.Configure(
+		"test",
+		testConfig(),
+		WithInvocationTimeout(0),
+		WithRateLimit(1, 1),
+		WithRateLimitTimeout(time.Second),
+		withClock(clock),
+	)
+
+	Expect(r.Call("test", nilFunc, nil)).To(BeNil())
+
+	go func() {
+		defer close(errors)
+		errors <- r.Call("test", nilFunc, nil)
+	}()
+
+	Consistently(errors).ShouldNot(Receive())
+	clock.BlockingAdvance(time.Second)
+	Eventually(errors).Should(Receive(BeNil()))
+}
+
 func (s *RegistrySuite) TestConcurrencyUnblocked(t sweet.T) {
 	var (
 		r       = NewRegistry()
@@ -196,7 +896,7 @@ func (s *RegistrySuite) TestConcurrencyUnblocked(t sweet.T) {
 		defer close(result)
 
 		result <- r.Call("test", nilFunc, func(err error) error {
-			Expect(err).To(Equal(ErrMaxConcurrency))
+			Expect(err).To(beError(ErrMaxConcurrency))
 			return nil
 		})
 	}()
@@ -249,7 +949,7 @@ func (s *RegistrySuite) TestConcurrencyTimeout(t sweet.T) {
 		defer close(result)
 
 		result <- r.Call("test", nilFunc, func(err error) error {
-			Expect(err).To(Equal(ErrMaxConcurrency))
+			Expect(err).To(beError(ErrMaxConcurrency))
 			return nil
 		})
 	}()
@@ -266,6 +966,162 @@ func (s *RegistrySuite) TestDoubleConfigure(t sweet.T) {
 	Expect(r.Configure("test")).To(Equal(ErrAlreadyConfigured))
 }
 
+func (s *RegistrySuite) TestConcurrencyCancel(t sweet.T) {
+	var (
+		clock       = glock.NewMockClock()
+		r           = newRegistryWithClock(clock)
+		started     = make(chan struct{}) // Signals start of f
+		block       = make(chan error, 5) // Blocks inside f
+		result      = make(chan error)
+		ctx, cancel = context.WithCancel(context.Background())
+	)
+
+	defer close(started)
+
+	f := func(ctx context.Context) error {
+		started <- struct{}{}
+		return <-block
+	}
+
+	r.Configure(
+		"test",
+		testConfig(),
+		WithMaxConcurrency(5),
+		WithMaxConcurrencyTimeout(time.Minute),
+		withClock(clock),
+	)
+
+	for i := 0; i < 5; i++ {
+		r.CallAsync("test", f, nil)
+		<-started
+	}
+
+	go func() {
+		defer close(result)
+		result <- r.CallContext(ctx, "test", nilFunc, nil)
+	}()
+
+	Consistently(result).ShouldNot(Receive())
+	cancel()
+	Eventually(result).Should(Receive(Equal(context.Canceled)))
+	close(block)
+}
+
+func (s *RegistrySuite) TestConcurrencyCallerDeadline(t sweet.T) {
+	var (
+		clock     = glock.NewMockClock()
+		r         = newRegistryWithClock(clock)
+		collector = newTestCollector()
+		started   = make(chan struct{})
+		block     = make(chan error)
+		reasons   = make(chan FallbackReason, 1)
+	)
+
+	r.Configure(
+		"test",
+		testConfig(),
+		WithMaxConcurrency(1),
+		WithMaxConcurrencyTimeout(time.Minute),
+		WithCollector(collector),
+		withClock(clock),
+	)
+
+	r.CallAsync("test", func(ctx context.Context) error {
+		close(started)
+		return <-block
+	}, nil)
+
+	<-started
+	defer close(block)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+
+	err := r.CallWithFallback(ctx, "test", nilFunc, func(ctx context.Context, name string, reason FallbackReason, err error) error {
+		reasons <- reason
+		return err
+	})
+
+	Expect(err).To(beError(ErrMaxConcurrency))
+	Expect(reasons).To(Receive(Equal(FallbackReasonRejection)))
+	Expect(collector.count(EventTypeRejection)).To(Equal(1))
+	Expect(collector.count(EventTypeCancelled)).To(Equal(0))
+}
+
+func (s *RegistrySuite) TestRateLimitCallerDeadline(t sweet.T) {
+	var (
+		clock     = glock.NewMockClock()
+		collector = newTestCollector()
+		r         = newRegistryWithClock(clock)
+	)
+
+	r.Configure(
+		"test",
+		testConfig(),
+		WithInvocationTimeout(0),
+		WithRateLimit(1, 1),
+		WithRateLimitTimeout(time.Minute),
+		WithCollector(collector),
+		withClock(clock),
+	)
+
+	Expect(r.Call("test", nilFunc, nil)).To(BeNil())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+
+	Expect(r.CallContext(ctx, "test", nilFunc, nil)).To(beError(ErrRateLimited))
+	Expect(collector.count(EventTypeRateLimited)).To(Equal(1))
+	Expect(collector.count(EventTypeCancelled)).To(Equal(0))
+}
+
+func (s *RegistrySuite) TestOnStateChange(t sweet.T) {
+	var (
+		r       = NewRegistry()
+		changes = []StateChange{}
+	)
+
+	r.Configure("test1", testConfig())
+	r.OnStateChange(func(change StateChange) { changes = append(changes, change) })
+	r.Configure("test2", testConfig(), WithName("ignored"))
+
+	for i := 0; i < 6; i++ {
+		r.Call("test1", errFunc, nil)
+		r.Call("test2", errFunc, nil)
+	}
+
+	Expect(changes).To(HaveLen(2))
+	Expect(changes[0].Name).To(Equal("test1"))
+	Expect(changes[0].To).To(Equal(StateOpen))
+	Expect(changes[1].Name).To(Equal("test2"))
+	Expect(changes[1].To).To(Equal(StateOpen))
+}
+
+func (s *RegistrySuite) TestInspection(t sweet.T) {
+	r := NewRegistry()
+	r.Configure("b", testConfig())
+	r.Configure("a", testConfig())
+	Expect(r.Names()).To(Equal([]string{"a", "b"}))
+
+	for i := 0; i < 6; i++ {
+		r.Call("a", errFunc, nil)
+	}
+
+	state, err := r.State("a")
+	Expect(err).To(BeNil())
+	Expect(state).To(Equal(StateOpen))
+
+	snapshot, err := r.Snapshot("b")
+	Expect(err).To(BeNil())
+	Expect(snapshot.Name).To(Equal("b"))
+	Expect(snapshot.State).To(Equal(StateClosed))
+
+	_, err = r.State("c")
+	Expect(err).To(Equal(ErrBreakerUnconfigured))
+	_, err = r.Snapshot("c")
+	Expect(err).To(Equal(ErrBreakerUnconfigured))
+}
+
 func (s *RegistrySuite) TestCallUnconfigured(t sweet.T) {
 	Expect(NewRegistry().Call("test", nilFunc, nil)).To(Equal(ErrBreakerUnconfigured))
 }
@@ -0,0 +1,71 @@
+package overcurrent
+
+import (
+	"context"
+	"errors"
+
+	"github.com/efritz/backoff"
+)
+
+// withRetries invokes the given call and re-invokes it after a failure according to
+// the breaker's retry policy. Retries stop once the call succeeds, the maximum number
+// of attempts is reached, the error is not retryable, the caller's context would
+// expire before the next attempt, or the breaker's retry budget is exhausted.
+func (cb *circuitBreaker) withRetries(ctx context.Context, call func() error) error {
+	var retryBackoff backoff.Backoff
+
+	for attempt := 1; ; attempt++ {
+		err := call()
+		if err == nil && attempt == 1 && cb.retryBudget != nil {
+			cb.retryBudget.deposit()
+		}
+
+		if err == nil || attempt >= cb.retryMaxAttempts || !cb.shouldRetry(ctx, err) {
+			return err
+		}
+
+		if retryBackoff == nil {
+			retryBackoff = cb.retryBackoff.Clone()
+			retryBackoff.Reset()
+		}
+
+		interval := retryBackoff.NextInterval()
+
+		if deadline, ok := ctx.Deadline(); ok && cb.clock.Now().Add(interval).After(deadline) {
+			return err
+		}
+
+		if cb.retryBudget != nil && !cb.retryBudget.withdraw() {
+			cb.collector.ReportCount(EventTypeRetryBudgetExhausted)
+			return &RetryBudgetError{Err: err}
+		}
+
+		select {
+		case <-cb.clock.After(interval):
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.Canceled) {
+				cb.collector.ReportCount(EventTypeCancelled)
+				return ctx.Err()
+			}
+
+			// The caller's deadline elapsed, so the last failure stands
+			return err
+		}
+
+		cb.collector.ReportCount(EventTypeRetry)
+	}
+}
+
+// shouldRetry determines if a failed call should be attempted again. Calls which
+// were rejected by the breaker or abandoned by the caller are never retried.
+func (cb *circuitBreaker) shouldRetry(ctx context.Context, err error) bool {
+	if errors.Is(err, ErrCircuitOpen) || errors.Is(err, ErrMaxAbandoned) || errors.Is(err, ErrMaxConcurrency) || errors.Is(err, ErrRateLimited) {
+		return false
+	}
+
+	if isCallerError(ctx, err) {
+		return false
+	}
+
+	return cb.retryInterpreter.ShouldTrip(err)
+}
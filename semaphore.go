@@ -1,52 +1,126 @@
 package overcurrent
 
 import (
+	"context"
+	"sync"
 	"time"
 
 	"github.com/efritz/glock"
 )
 
+// semaphore bounds the number of concurrent invocations of a breaker function
+// by the current limit of a concurrency limiter. Routines waiting for a token
+// are granted one in the order in which they began waiting.
 type semaphore struct {
-	clock glock.Clock
-	ch    chan struct{}
+	clock    glock.Clock
+	limiter  ConcurrencyLimiter
+	mutex    sync.Mutex
+	acquired int
+	waiters  []chan struct{}
 }
 
 func newSemaphore(clock glock.Clock, capacity int) *semaphore {
-	s := &semaphore{
-		clock: clock,
-		ch:    make(chan struct{}, capacity),
-	}
+	return newLimitedSemaphore(clock, NewFixedConcurrencyLimiter(capacity))
+}
 
-	for i := 0; i < capacity; i++ {
-		s.signal()
+func newLimitedSemaphore(clock glock.Clock, limiter ConcurrencyLimiter) *semaphore {
+	return &semaphore{
+		clock:   clock,
+		limiter: limiter,
 	}
-
-	return s
 }
 
-func (s *semaphore) wait(timeout time.Duration, collector MetricCollector) bool {
-	select {
-	case <-s.ch:
+func (s *semaphore) wait(ctx context.Context, timeout time.Duration, collector MetricCollector) bool {
+	s.mutex.Lock()
+
+	if len(s.waiters) == 0 && s.acquired < s.limiter.Limit() {
+		s.acquired++
+		s.mutex.Unlock()
 		return true
-	default:
 	}
 
 	if timeout == 0 {
+		s.mutex.Unlock()
 		return false
 	}
 
+	ch := make(chan struct{})
+	s.waiters = append(s.waiters, ch)
+	s.mutex.Unlock()
+
 	collector.ReportCount(EventTypeSemaphoreQueued)
 	defer collector.ReportCount(EventTypeSemaphoreDequeued)
 
 	select {
-	case <-s.ch:
+	case <-ch:
 		return true
 
 	case <-s.clock.After(timeout):
-		return false
+	case <-ctx.Done():
+	}
+
+	if !s.removeWaiter(ch) {
+		// A token was granted concurrently with the timeout
+		// or cancellation, so hand it to the next waiter.
+		s.signal()
 	}
+
+	return false
 }
 
 func (s *semaphore) signal() {
-	s.ch <- struct{}{}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.acquired--
+	s.grant()
+}
+
+// inFlight returns the number of tokens currently held.
+func (s *semaphore) inFlight() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.acquired
+}
+
+// observe passes the result of an invocation to the limiter and grants tokens
+// to waiting routines if the limit has grown. Returns the new limit along with
+// a flag indicating whether or not the limit has changed.
+func (s *semaphore) observe(rtt time.Duration, inFlight int, dropped bool) (int, bool) {
+	previous := s.limiter.Limit()
+	s.limiter.Observe(rtt, inFlight, dropped)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.grant()
+	limit := s.limiter.Limit()
+	return limit, limit != previous
+}
+
+// grant hands tokens to waiting routines while the limit allows. This method
+// assumes the mutex is held.
+func (s *semaphore) grant() {
+	for len(s.waiters) > 0 && s.acquired < s.limiter.Limit() {
+		s.acquired++
+		close(s.waiters[0])
+		s.waiters = s.waiters[1:]
+	}
+}
+
+// removeWaiter stops the given routine from waiting for a token. Returns false
+// if a token has already been granted to it.
+func (s *semaphore) removeWaiter(ch chan struct{}) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i, waiter := range s.waiters {
+		if waiter == ch {
+			s.waiters = append(s.waiters[:i], s.waiters[i+1:]...)
+			return true
+		}
+	}
+
+	return false
 }
@@ -1,6 +1,7 @@
 package overcurrent
 
 import (
+	"context"
 	"time"
 
 	"github.com/aphistic/sweet"
@@ -18,12 +19,12 @@ func (s *SemaphoreSuite) TestWaitSignal(t sweet.T) {
 	)
 
 	for i := 0; i < 10; i++ {
-		Expect(semaphore.wait(time.Second, defaultCollector)).To(BeTrue())
+		Expect(semaphore.wait(context.Background(), time.Second, defaultCollector)).To(BeTrue())
 	}
 
 	go func() {
 		defer close(sync)
-		semaphore.wait(time.Second, defaultCollector)
+		semaphore.wait(context.Background(), time.Second, defaultCollector)
 	}()
 
 	Consistently(sync).ShouldNot(Receive())
@@ -35,7 +36,7 @@ func (s *SemaphoreSuite) TestWaitSignal(t sweet.T) {
 	}
 
 	for i := 0; i < 10; i++ {
-		Expect(semaphore.wait(time.Second, defaultCollector)).To(BeTrue())
+		Expect(semaphore.wait(context.Background(), time.Second, defaultCollector)).To(BeTrue())
 	}
 }
 
@@ -47,12 +48,12 @@ func (s *SemaphoreSuite) TestWaitTimeout(t sweet.T) {
 	)
 
 	for i := 0; i < 10; i++ {
-		Expect(semaphore.wait(time.Second, defaultCollector)).To(BeTrue())
+		Expect(semaphore.wait(context.Background(), time.Second, defaultCollector)).To(BeTrue())
 	}
 
 	go func() {
 		defer close(value)
-		value <- semaphore.wait(time.Minute, defaultCollector)
+		value <- semaphore.wait(context.Background(), time.Minute, defaultCollector)
 	}()
 
 	Consistently(value).ShouldNot(Receive())
@@ -60,18 +61,102 @@ func (s *SemaphoreSuite) TestWaitTimeout(t sweet.T) {
 	Eventually(value).Should(Receive(BeFalse()))
 }
 
+func (s *SemaphoreSuite) TestWaitCancel(t sweet.T) {
+	var (
+		clock       = glock.NewMockClock()
+		semaphore   = newSemaphore(clock, 10)
+		value       = make(chan bool)
+		ctx, cancel = context.WithCancel(context.Background())
+	)
+
+	for i := 0; i < 10; i++ {
+		Expect(semaphore.wait(ctx, time.Second, defaultCollector)).To(BeTrue())
+	}
+
+	go func() {
+		defer close(value)
+		value <- semaphore.wait(ctx, time.Minute, defaultCollector)
+	}()
+
+	Consistently(value).ShouldNot(Receive())
+	cancel()
+	Eventually(value).Should(Receive(BeFalse()))
+}
+
 func (s *SemaphoreSuite) TestNoWait(t sweet.T) {
 	var (
 		clock     = glock.NewMockClock()
 		semaphore = newSemaphore(clock, 3)
 	)
 
-	Expect(semaphore.wait(0, defaultCollector)).To(BeTrue())
-	Expect(semaphore.wait(0, defaultCollector)).To(BeTrue())
-	Expect(semaphore.wait(0, defaultCollector)).To(BeTrue())
-	Expect(semaphore.wait(0, defaultCollector)).To(BeFalse())
+	Expect(semaphore.wait(context.Background(), 0, defaultCollector)).To(BeTrue())
+	Expect(semaphore.wait(context.Background(), 0, defaultCollector)).To(BeTrue())
+	Expect(semaphore.wait(context.Background(), 0, defaultCollector)).To(BeTrue())
+	Expect(semaphore.wait(context.Background(), 0, defaultCollector)).To(BeFalse())
+
+	semaphore.signal()
+	Expect(semaphore.wait(context.Background(), 0, defaultCollector)).To(BeTrue())
+	Expect(semaphore.wait(context.Background(), 0, defaultCollector)).To(BeFalse())
+}
+
+func (s *SemaphoreSuite) TestResize(t sweet.T) {
+	var (
+		clock     = glock.NewMockClock()
+		semaphore = newLimitedSemaphore(clock, NewAIMDConcurrencyLimiter(2, 1, 10, 0.5))
+		value     = make(chan bool)
+	)
+
+	Expect(semaphore.wait(context.Background(), 0, defaultCollector)).To(BeTrue())
+	Expect(semaphore.wait(context.Background(), 0, defaultCollector)).To(BeTrue())
+	Expect(semaphore.inFlight()).To(Equal(2))
+
+	go func() {
+		defer close(value)
+		value <- semaphore.wait(context.Background(), time.Minute, defaultCollector)
+	}()
+
+	Consistently(value).ShouldNot(Receive())
+
+	// Growing the limit grants a token to the waiting routine
+	limit, changed := semaphore.observe(time.Millisecond, 2, false)
+	Expect(limit).To(Equal(3))
+	Expect(changed).To(BeTrue())
+	Eventually(value).Should(Receive(BeTrue()))
+	Expect(semaphore.inFlight()).To(Equal(3))
+
+	// Shrinking the limit does not revoke tokens
+	limit, changed = semaphore.observe(time.Second, 3, true)
+	Expect(limit).To(Equal(1))
+	Expect(changed).To(BeTrue())
+	Expect(semaphore.inFlight()).To(Equal(3))
+
+	semaphore.signal()
+	semaphore.signal()
+	Expect(semaphore.wait(context.Background(), 0, defaultCollector)).To(BeFalse())
+
+	semaphore.signal()
+	Expect(semaphore.wait(context.Background(), 0, defaultCollector)).To(BeTrue())
+}
+
+func (s *SemaphoreSuite) TestWaitTimeoutRemovesWaiter(t sweet.T) {
+	var (
+		clock     = glock.NewMockClock()
+		semaphore = newSemaphore(clock, 1)
+		value     = make(chan bool)
+	)
+
+	Expect(semaphore.wait(context.Background(), 0, defaultCollector)).To(BeTrue())
+
+	go func() {
+		defer close(value)
+		value <- semaphore.wait(context.Background(), time.Minute, defaultCollector)
+	}()
+
+	clock.BlockingAdvance(time.Minute)
+	Eventually(value).Should(Receive(BeFalse()))
 
+	// The token is not handed to the routine which stopped waiting
 	semaphore.signal()
-	Expect(semaphore.wait(0, defaultCollector)).To(BeTrue())
-	Expect(semaphore.wait(0, defaultCollector)).To(BeFalse())
+	Expect(semaphore.inFlight()).To(Equal(0))
+	Expect(semaphore.wait(context.Background(), 0, defaultCollector)).To(BeTrue())
 }
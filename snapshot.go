@@ -0,0 +1,45 @@
+package overcurrent
+
+import (
+	"fmt"
+	"time"
+)
+
+// Snapshot is a point-in-time view of a circuit breaker. Time values are the
+// zero time if not applicable (e.g. no failure has occurred, or the breaker is
+// not open).
+type Snapshot struct {
+	// Name is the name of the breaker.
+	Name string
+
+	// State is the current state of the breaker.
+	State CircuitState
+
+	// LastFailureTime is the time the most recent failure was marked.
+	LastFailureTime time.Time
+
+	// ResetTimeout is the duration the breaker stays open after a failure
+	// before it becomes eligible to transition into the half-closed state.
+	ResetTimeout time.Duration
+
+	// NextAttemptTime is the time at which an open breaker becomes eligible
+	// to transition into the half-closed state.
+	NextAttemptTime time.Time
+
+	// TripCondition is a human-readable summary of the trip condition.
+	TripCondition string
+
+	// Abandoned is the number of invocations which are still running after
+	// the breaker stopped waiting for them.
+	Abandoned int
+}
+
+// describeTripCondition returns the summary of a trip condition which implements
+// fmt.Stringer, or the name of the trip condition's type otherwise.
+func describeTripCondition(tripCondition TripCondition) string {
+	if stringer, ok := tripCondition.(fmt.Stringer); ok {
+		return stringer.String()
+	}
+
+	return fmt.Sprintf("%T", tripCondition)
+}
@@ -0,0 +1,70 @@
+package overcurrent
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/efritz/glock"
+)
+
+type (
+	// adaptiveThrottle implements client-side adaptive throttling. Calls are
+	// rejected locally with a probability that grows as the fraction of recent
+	// requests accepted by the dependency shrinks.
+	adaptiveThrottle struct {
+		mutex       sync.Mutex
+		clock       glock.Clock
+		requests    rollingWindow
+		k           float64
+		probability float64
+	}
+)
+
+const adaptiveThrottleBuckets = 10
+
+// Counters of the rolling window of an adaptiveThrottle.
+const (
+	throttleRequests = iota
+	throttleAccepts
+)
+
+func newAdaptiveThrottle(k float64, window time.Duration, clock glock.Clock) *adaptiveThrottle {
+	return &adaptiveThrottle{
+		clock:    clock,
+		requests: newRollingWindow(window, adaptiveThrottleBuckets),
+		k:        k,
+	}
+}
+
+// request records a request and returns the probability with which it should
+// be rejected, along with a flag indicating whether or not the probability has
+// changed since the previous request. The probability is computed over the
+// requests within the window as max(0, (requests - k*accepts) / (requests + 1)).
+func (t *adaptiveThrottle) request() (float64, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	var (
+		now               = t.clock.Now()
+		requests, accepts = t.requests.sums(now)
+	)
+
+	t.requests.add(now, throttleRequests)
+
+	probability := math.Max(0, (float64(requests)-t.k*float64(accepts))/float64(requests+1))
+	if probability == t.probability {
+		return probability, false
+	}
+
+	t.probability = probability
+	return probability, true
+}
+
+// accept records a request which was accepted by the dependency.
+func (t *adaptiveThrottle) accept() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.requests.add(t.clock.Now(), throttleAccepts)
+}
@@ -0,0 +1,75 @@
+package overcurrent
+
+import (
+	"time"
+
+	"github.com/aphistic/sweet"
+	"github.com/efritz/glock"
+	. "github.com/onsi/gomega"
+)
+
+type ThrottleSuite struct{}
+
+func (s *ThrottleSuite) TestProbability(t sweet.T) {
+	throttle := newAdaptiveThrottle(2, 10*time.Second, glock.NewMockClock())
+
+	for i := 0; i < 10; i++ {
+		probability, changed := throttle.request()
+		Expect(probability).To(Equal(0.0))
+		Expect(changed).To(BeFalse())
+		throttle.accept()
+	}
+
+	// 20 requests, 10 accepts
+	for i := 0; i < 10; i++ {
+		throttle.request()
+	}
+
+	probability, changed := throttle.request()
+	Expect(probability).To(Equal(0.0))
+	Expect(changed).To(BeFalse())
+
+	// 29 requests, 10 accepts
+	for i := 0; i < 8; i++ {
+		throttle.request()
+	}
+
+	probability, changed = throttle.request()
+	Expect(probability).To(BeNumerically("~", 9.0/30.0))
+	Expect(changed).To(BeTrue())
+}
+
+func (s *ThrottleSuite) TestWindow(t sweet.T) {
+	var (
+		clock    = glock.NewMockClock()
+		throttle = newAdaptiveThrottle(1, 10*time.Second, clock)
+	)
+
+	for i := 0; i < 9; i++ {
+		throttle.request()
+	}
+
+	probability, _ := throttle.request()
+	Expect(probability).To(BeNumerically("~", 0.9))
+
+	clock.Advance(5 * time.Second)
+	probability, _ = throttle.request()
+	Expect(probability).To(BeNumerically("~", 10.0/11.0))
+
+	// The first ten requests fall out of the window
+	clock.Advance(5 * time.Second)
+	probability, changed := throttle.request()
+	Expect(probability).To(BeNumerically("~", 0.5))
+	Expect(changed).To(BeTrue())
+
+	clock.Advance(time.Minute)
+	probability, _ = throttle.request()
+	Expect(probability).To(Equal(0.0))
+}
+
+func (s *ThrottleSuite) TestZeroWindow(t sweet.T) {
+	throttle := newAdaptiveThrottle(2, 0, glock.NewMockClock())
+
+	Expect(func() { throttle.request() }).NotTo(Panic())
+	Expect(throttle.accept).NotTo(Panic())
+}
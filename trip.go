@@ -1,6 +1,8 @@
 package overcurrent
 
 import (
+	"fmt"
+	"math"
 	"time"
 
 	"github.com/efritz/glock"
@@ -25,6 +27,19 @@ type (
 		ShouldTrip() bool
 	}
 
+	// DurationAwareTripCondition is an optional extension of TripCondition for
+	// conditions which depend on how long calls take. When the duration of a call
+	// is known, the breaker invokes these methods instead of Success and Failure.
+	DurationAwareTripCondition interface {
+		TripCondition
+
+		// Invoked in place of Success with the duration of the call.
+		SuccessWithDuration(duration time.Duration)
+
+		// Invoked in place of Failure with the duration of the call.
+		FailureWithDuration(duration time.Duration)
+	}
+
 	// ConsecutiveFailureTripCondition is a trip condition that trips the circuit
 	// breaker after a configurable number of failures occur in a row. A single
 	// successful call will break the failure chain.
@@ -53,6 +68,63 @@ type (
 		threshold float64
 		failures  int
 	}
+
+	// SlowCallRateTripCondition is a trip condition that trips the circuit breaker
+	// after the share of calls within a configurable rolling window which took at
+	// least a configurable duration exceeds a percentage threshold. Failed calls
+	// are counted by their duration like any other call. Calls whose duration is
+	// not known are counted as fast calls.
+	SlowCallRateTripCondition struct {
+		conditionClock
+		log               []slowCallRecord
+		window            time.Duration
+		durationThreshold time.Duration
+		threshold         float64
+		minimumCalls      int
+		slow              int
+	}
+
+	// RollingErrorPercentageTripCondition is a trip condition that trips the circuit
+	// breaker after the percentage of failed calls within a rolling time window
+	// exceeds a threshold, provided that enough calls were made within the window.
+	// The window is divided into a fixed number of buckets, and a bucket's calls
+	// fall out of the window all at once. This mirrors the default rule of Hystrix.
+	RollingErrorPercentageTripCondition struct {
+		conditionClock
+		calls         rollingWindow
+		threshold     float64
+		requestVolume int
+	}
+
+	slowCallRecord struct {
+		time time.Time
+		slow bool
+	}
+
+	// configReporter is implemented by trip conditions whose parameters are
+	// reported to metric collectors as part of the breaker configuration.
+	configReporter interface {
+		reportConfig(config *BreakerConfig)
+	}
+
+	// clockSetter is implemented by trip conditions which measure time so
+	// that they can share the clock of the breaker they are attached to.
+	clockSetter interface {
+		setClock(clock glock.Clock)
+	}
+
+	// conditionClock is a clock for trip conditions that are constructed without
+	// an explicit clock. It takes on the clock of the breaker when attached to one
+	// and falls back to the real clock otherwise.
+	conditionClock struct {
+		clock glock.Clock
+	}
+)
+
+// Counters of the rolling window of a RollingErrorPercentageTripCondition.
+const (
+	rollingSuccesses = iota
+	rollingFailures
 )
 
 // NewConsecutiveFailureTripCondition creates a ConsecutiveFailureTripCondition.
@@ -75,6 +147,10 @@ func (tc *ConsecutiveFailureTripCondition) ShouldTrip() bool {
 	return tc.count >= tc.threshold
 }
 
+func (tc *ConsecutiveFailureTripCondition) String() string {
+	return fmt.Sprintf("%d of %d consecutive failures", tc.count, tc.threshold)
+}
+
 // NewWindowFailureTripCondition creates a WindowFailureTripCondition.
 func NewWindowFailureTripCondition(window time.Duration, threshold int) TripCondition {
 	return newWindowFailureTripConditionWithClock(window, threshold, glock.NewRealClock())
@@ -104,6 +180,19 @@ func (tc *WindowFailureTripCondition) ShouldTrip() bool {
 	return len(tc.log) >= tc.threshold
 }
 
+func (tc *WindowFailureTripCondition) String() string {
+	// Count without pruning so that describing the trip
+	// condition does not modify its state.
+	count := 0
+	for _, t := range tc.log {
+		if tc.clock.Now().Sub(t) < tc.window {
+			count++
+		}
+	}
+
+	return fmt.Sprintf("%d of %d failures within %s", count, tc.threshold, tc.window)
+}
+
 // NewPercentageFailureFailureTripCondition creates a PercentageFailureTripCondition.
 func NewPercentageFailureTripCondition(window int, threshold float64) TripCondition {
 	return &PercentageFailureTripCondition{
@@ -130,6 +219,16 @@ func (tc *PercentageFailureTripCondition) ShouldTrip() bool {
 	return float64(tc.failures)/float64(len(tc.log)) >= tc.threshold
 }
 
+func (tc *PercentageFailureTripCondition) String() string {
+	return fmt.Sprintf(
+		"%d failures of the last %d of %d calls (threshold %.0f%%)",
+		tc.failures,
+		len(tc.log),
+		tc.window,
+		tc.threshold*100,
+	)
+}
+
 func (tc *PercentageFailureTripCondition) addToLog(value bool) {
 	tc.log = append(tc.log, value)
 
@@ -145,3 +244,168 @@ func (tc *PercentageFailureTripCondition) addToLog(value bool) {
 		tc.log = tc.log[1:]
 	}
 }
+
+// NewSlowCallRateTripCondition creates a SlowCallRateTripCondition. Calls which
+// take at least durationThreshold are considered slow. The breaker trips once the
+// share of slow calls within the window reaches threshold (a value in [0, 1]), but
+// only if at least minimumCalls calls were made within the window.
+func NewSlowCallRateTripCondition(window, durationThreshold time.Duration, threshold float64, minimumCalls int) TripCondition {
+	return newSlowCallRateTripConditionWithClock(window, durationThreshold, threshold, minimumCalls, nil)
+}
+
+func newSlowCallRateTripConditionWithClock(window, durationThreshold time.Duration, threshold float64, minimumCalls int, clock glock.Clock) TripCondition {
+	return &SlowCallRateTripCondition{
+		conditionClock:    conditionClock{clock: clock},
+		log:               []slowCallRecord{},
+		window:            window,
+		durationThreshold: durationThreshold,
+		threshold:         threshold,
+		minimumCalls:      minimumCalls,
+	}
+}
+
+func (tc *SlowCallRateTripCondition) Success() {
+	tc.addToLog(false)
+}
+
+func (tc *SlowCallRateTripCondition) Failure() {
+	tc.addToLog(false)
+}
+
+func (tc *SlowCallRateTripCondition) SuccessWithDuration(duration time.Duration) {
+	tc.addToLog(duration >= tc.durationThreshold)
+}
+
+func (tc *SlowCallRateTripCondition) FailureWithDuration(duration time.Duration) {
+	tc.addToLog(duration >= tc.durationThreshold)
+}
+
+func (tc *SlowCallRateTripCondition) ShouldTrip() bool {
+	tc.prune()
+
+	if len(tc.log) == 0 || len(tc.log) < tc.minimumCalls {
+		return false
+	}
+
+	return float64(tc.slow)/float64(len(tc.log)) >= tc.threshold
+}
+
+func (tc *SlowCallRateTripCondition) String() string {
+	// Count without pruning so that describing the trip
+	// condition does not modify its state.
+	now := tc.now()
+
+	calls, slow := 0, 0
+	for _, record := range tc.log {
+		if now.Sub(record.time) < tc.window {
+			calls++
+
+			if record.slow {
+				slow++
+			}
+		}
+	}
+
+	return fmt.Sprintf(
+		"%d of %d calls slower than %s within %s (threshold %.0f%%)",
+		slow,
+		calls,
+		tc.durationThreshold,
+		tc.window,
+		tc.threshold*100,
+	)
+}
+
+func (tc *SlowCallRateTripCondition) addToLog(slow bool) {
+	tc.log = append(tc.log, slowCallRecord{time: tc.now(), slow: slow})
+
+	if slow {
+		tc.slow++
+	}
+
+	tc.prune()
+}
+
+func (tc *SlowCallRateTripCondition) prune() {
+	now := tc.now()
+
+	for len(tc.log) != 0 && now.Sub(tc.log[0].time) >= tc.window {
+		if tc.log[0].slow {
+			tc.slow--
+		}
+
+		tc.log = tc.log[1:]
+	}
+}
+
+// NewRollingErrorPercentageTripCondition creates a RollingErrorPercentageTripCondition.
+// The window is divided into the given number of buckets. The breaker trips once the
+// share of failed calls within the window reaches threshold (a value in [0, 1]), but
+// only if at least requestVolume calls were made within the window. The window is
+// clamped so that each bucket is at least a nanosecond wide.
+func NewRollingErrorPercentageTripCondition(window time.Duration, buckets int, threshold float64, requestVolume int) TripCondition {
+	return newRollingErrorPercentageTripConditionWithClock(window, buckets, threshold, requestVolume, nil)
+}
+
+func newRollingErrorPercentageTripConditionWithClock(window time.Duration, buckets int, threshold float64, requestVolume int, clock glock.Clock) TripCondition {
+	return &RollingErrorPercentageTripCondition{
+		conditionClock: conditionClock{clock: clock},
+		calls:          newRollingWindow(window, buckets),
+		threshold:      threshold,
+		requestVolume:  requestVolume,
+	}
+}
+
+func (tc *RollingErrorPercentageTripCondition) Success() {
+	tc.calls.add(tc.now(), rollingSuccesses)
+}
+
+func (tc *RollingErrorPercentageTripCondition) Failure() {
+	tc.calls.add(tc.now(), rollingFailures)
+}
+
+func (tc *RollingErrorPercentageTripCondition) ShouldTrip() bool {
+	calls, failures := tc.counts()
+	if calls == 0 || calls < tc.requestVolume {
+		return false
+	}
+
+	return float64(failures)/float64(calls) >= tc.threshold
+}
+
+func (tc *RollingErrorPercentageTripCondition) String() string {
+	calls, failures := tc.counts()
+
+	return fmt.Sprintf(
+		"%d failures of %d calls within %s (threshold %.0f%%, volume %d)",
+		failures,
+		calls,
+		tc.calls.window,
+		tc.threshold*100,
+		tc.requestVolume,
+	)
+}
+
+func (tc *RollingErrorPercentageTripCondition) reportConfig(config *BreakerConfig) {
+	config.ErrorThresholdPercentage = int(math.Round(tc.threshold * 100))
+	config.RequestVolumeThreshold = tc.requestVolume
+}
+
+func (tc *RollingErrorPercentageTripCondition) counts() (calls, failures int) {
+	successes, failures := tc.calls.sums(tc.now())
+	return successes + failures, failures
+}
+
+func (c *conditionClock) setClock(clock glock.Clock) {
+	if c.clock == nil {
+		c.clock = clock
+	}
+}
+
+func (c *conditionClock) now() time.Time {
+	if c.clock == nil {
+		c.clock = glock.NewRealClock()
+	}
+
+	return c.clock.Now()
+}
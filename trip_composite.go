@@ -0,0 +1,237 @@
+package overcurrent
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/efritz/glock"
+)
+
+type (
+	// AnyOfTripCondition is a trip condition that trips the circuit breaker when
+	// at least one of its child trip conditions would trip.
+	AnyOfTripCondition struct {
+		compositeTripCondition
+	}
+
+	// AllOfTripCondition is a trip condition that trips the circuit breaker only
+	// when all of its child trip conditions would trip.
+	AllOfTripCondition struct {
+		compositeTripCondition
+	}
+
+	// NegatedTripCondition is a trip condition that trips the circuit breaker only
+	// when its child trip condition would not trip.
+	NegatedTripCondition struct {
+		compositeTripCondition
+	}
+
+	// MinimumVolumeTripCondition is a trip condition that trips the circuit breaker
+	// when its child trip condition would trip, but only if a minimum number of
+	// calls were made within a rolling window.
+	MinimumVolumeTripCondition struct {
+		compositeTripCondition
+		clock     conditionClock
+		log       []time.Time
+		window    time.Duration
+		threshold int
+	}
+
+	// compositeTripCondition forwards the results of calls to each of its child
+	// trip conditions. The duration of a call is forwarded to those children
+	// which are duration-aware.
+	compositeTripCondition struct {
+		conditions []TripCondition
+	}
+)
+
+// AnyOf creates an AnyOfTripCondition.
+func AnyOf(conditions ...TripCondition) TripCondition {
+	return &AnyOfTripCondition{compositeTripCondition{conditions}}
+}
+
+func (tc *AnyOfTripCondition) ShouldTrip() bool {
+	for _, condition := range tc.conditions {
+		if condition.ShouldTrip() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (tc *AnyOfTripCondition) String() string {
+	return fmt.Sprintf("any of (%s)", tc.describe())
+}
+
+// AllOf creates an AllOfTripCondition.
+func AllOf(conditions ...TripCondition) TripCondition {
+	return &AllOfTripCondition{compositeTripCondition{conditions}}
+}
+
+func (tc *AllOfTripCondition) ShouldTrip() bool {
+	if len(tc.conditions) == 0 {
+		return false
+	}
+
+	for _, condition := range tc.conditions {
+		if !condition.ShouldTrip() {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (tc *AllOfTripCondition) String() string {
+	return fmt.Sprintf("all of (%s)", tc.describe())
+}
+
+// Negate creates a NegatedTripCondition.
+func Negate(condition TripCondition) TripCondition {
+	return &NegatedTripCondition{compositeTripCondition{[]TripCondition{condition}}}
+}
+
+func (tc *NegatedTripCondition) ShouldTrip() bool {
+	return !tc.conditions[0].ShouldTrip()
+}
+
+func (tc *NegatedTripCondition) String() string {
+	return fmt.Sprintf("not (%s)", tc.describe())
+}
+
+// MinimumVolume creates a MinimumVolumeTripCondition which requires at least
+// threshold calls within the window before the given condition can trip.
+func MinimumVolume(threshold int, window time.Duration, condition TripCondition) TripCondition {
+	return newMinimumVolumeWithClock(threshold, window, condition, nil)
+}
+
+func newMinimumVolumeWithClock(threshold int, window time.Duration, condition TripCondition, clock glock.Clock) TripCondition {
+	return &MinimumVolumeTripCondition{
+		compositeTripCondition: compositeTripCondition{[]TripCondition{condition}},
+		clock:                  conditionClock{clock: clock},
+		log:                    []time.Time{},
+		window:                 window,
+		threshold:              threshold,
+	}
+}
+
+func (tc *MinimumVolumeTripCondition) Success() {
+	tc.record()
+	tc.compositeTripCondition.Success()
+}
+
+func (tc *MinimumVolumeTripCondition) Failure() {
+	tc.record()
+	tc.compositeTripCondition.Failure()
+}
+
+func (tc *MinimumVolumeTripCondition) SuccessWithDuration(duration time.Duration) {
+	tc.record()
+	tc.compositeTripCondition.SuccessWithDuration(duration)
+}
+
+func (tc *MinimumVolumeTripCondition) FailureWithDuration(duration time.Duration) {
+	tc.record()
+	tc.compositeTripCondition.FailureWithDuration(duration)
+}
+
+func (tc *MinimumVolumeTripCondition) ShouldTrip() bool {
+	now := tc.clock.now()
+
+	for len(tc.log) != 0 && now.Sub(tc.log[0]) >= tc.window {
+		tc.log = tc.log[1:]
+	}
+
+	return len(tc.log) >= tc.threshold && tc.conditions[0].ShouldTrip()
+}
+
+func (tc *MinimumVolumeTripCondition) String() string {
+	// Count without pruning so that describing the trip
+	// condition does not modify its state.
+	now := tc.clock.now()
+
+	count := 0
+	for _, t := range tc.log {
+		if now.Sub(t) < tc.window {
+			count++
+		}
+	}
+
+	return fmt.Sprintf("%s (%d of %d calls within %s)", tc.describe(), count, tc.threshold, tc.window)
+}
+
+func (tc *MinimumVolumeTripCondition) setClock(clock glock.Clock) {
+	tc.clock.setClock(clock)
+	tc.compositeTripCondition.setClock(clock)
+}
+
+func (tc *MinimumVolumeTripCondition) reportConfig(config *BreakerConfig) {
+	tc.compositeTripCondition.reportConfig(config)
+
+	if tc.threshold > config.RequestVolumeThreshold {
+		config.RequestVolumeThreshold = tc.threshold
+	}
+}
+
+func (tc *MinimumVolumeTripCondition) record() {
+	tc.log = append(tc.log, tc.clock.now())
+}
+
+func (tc *compositeTripCondition) Success() {
+	for _, condition := range tc.conditions {
+		condition.Success()
+	}
+}
+
+func (tc *compositeTripCondition) Failure() {
+	for _, condition := range tc.conditions {
+		condition.Failure()
+	}
+}
+
+func (tc *compositeTripCondition) SuccessWithDuration(duration time.Duration) {
+	for _, condition := range tc.conditions {
+		if durationAware, ok := condition.(DurationAwareTripCondition); ok {
+			durationAware.SuccessWithDuration(duration)
+		} else {
+			condition.Success()
+		}
+	}
+}
+
+func (tc *compositeTripCondition) FailureWithDuration(duration time.Duration) {
+	for _, condition := range tc.conditions {
+		if durationAware, ok := condition.(DurationAwareTripCondition); ok {
+			durationAware.FailureWithDuration(duration)
+		} else {
+			condition.Failure()
+		}
+	}
+}
+
+func (tc *compositeTripCondition) setClock(clock glock.Clock) {
+	for _, condition := range tc.conditions {
+		if setter, ok := condition.(clockSetter); ok {
+			setter.setClock(clock)
+		}
+	}
+}
+
+func (tc *compositeTripCondition) reportConfig(config *BreakerConfig) {
+	for _, condition := range tc.conditions {
+		if reporter, ok := condition.(configReporter); ok {
+			reporter.reportConfig(config)
+		}
+	}
+}
+
+func (tc *compositeTripCondition) describe() string {
+	descriptions := make([]string, 0, len(tc.conditions))
+	for _, condition := range tc.conditions {
+		descriptions = append(descriptions, describeTripCondition(condition))
+	}
+
+	return strings.Join(descriptions, "; ")
+}
@@ -0,0 +1,168 @@
+package overcurrent
+
+import (
+	"time"
+
+	"github.com/aphistic/sweet"
+	"github.com/efritz/glock"
+	. "github.com/onsi/gomega"
+)
+
+type TripCompositeSuite struct{}
+
+func (s *TripCompositeSuite) TestAnyOf(t sweet.T) {
+	tc := AnyOf(
+		NewConsecutiveFailureTripCondition(5),
+		NewPercentageFailureTripCondition(4, 0.5),
+	)
+
+	Expect(tc.ShouldTrip()).To(BeFalse())
+	tc.Failure()
+	tc.Success()
+	tc.Failure()
+	Expect(tc.ShouldTrip()).To(BeFalse())
+	tc.Success()
+	Expect(tc.ShouldTrip()).To(BeTrue())
+
+	times(4, tc.Success)
+	Expect(tc.ShouldTrip()).To(BeFalse())
+	times(5, tc.Failure)
+	Expect(tc.ShouldTrip()).To(BeTrue())
+}
+
+func (s *TripCompositeSuite) TestAllOf(t sweet.T) {
+	var (
+		clock = glock.NewMockClock()
+		tc    = AllOf(
+			NewConsecutiveFailureTripCondition(3),
+			newWindowFailureTripConditionWithClock(time.Minute, 5, clock),
+		)
+	)
+
+	times(3, tc.Failure)
+	Expect(tc.ShouldTrip()).To(BeFalse())
+	tc.Success()
+	times(2, tc.Failure)
+	Expect(tc.ShouldTrip()).To(BeFalse())
+	tc.Failure()
+	Expect(tc.ShouldTrip()).To(BeTrue())
+
+	clock.Advance(time.Minute)
+	Expect(tc.ShouldTrip()).To(BeFalse())
+}
+
+func (s *TripCompositeSuite) TestAllOfEmpty(t sweet.T) {
+	Expect(AllOf().ShouldTrip()).To(BeFalse())
+}
+
+func (s *TripCompositeSuite) TestNegate(t sweet.T) {
+	tc := Negate(NewConsecutiveFailureTripCondition(2))
+
+	Expect(tc.ShouldTrip()).To(BeTrue())
+	tc.Failure()
+	Expect(tc.ShouldTrip()).To(BeTrue())
+	tc.Failure()
+	Expect(tc.ShouldTrip()).To(BeFalse())
+	tc.Success()
+	Expect(tc.ShouldTrip()).To(BeTrue())
+	Expect(describeTripCondition(tc)).To(Equal("not (0 of 2 consecutive failures)"))
+}
+
+func (s *TripCompositeSuite) TestMinimumVolume(t sweet.T) {
+	var (
+		clock = glock.NewMockClock()
+		tc    = newMinimumVolumeWithClock(
+			5,
+			time.Minute,
+			NewConsecutiveFailureTripCondition(2),
+			clock,
+		)
+	)
+
+	times(3, tc.Success)
+	tc.Failure()
+	Expect(tc.ShouldTrip()).To(BeFalse())
+	tc.Failure()
+	Expect(tc.ShouldTrip()).To(BeTrue())
+
+	clock.Advance(time.Minute)
+	Expect(tc.ShouldTrip()).To(BeFalse())
+}
+
+func (s *TripCompositeSuite) TestForwardsDurations(t sweet.T) {
+	tc := AnyOf(
+		NewConsecutiveFailureTripCondition(3),
+		MinimumVolume(2, time.Minute, NewSlowCallRateTripCondition(time.Minute, time.Second, 0.5, 1)),
+	).(DurationAwareTripCondition)
+
+	tc.SuccessWithDuration(time.Second)
+	Expect(tc.ShouldTrip()).To(BeFalse())
+	tc.FailureWithDuration(time.Millisecond)
+	Expect(tc.ShouldTrip()).To(BeTrue())
+}
+
+func (s *TripCompositeSuite) TestBreakerClock(t sweet.T) {
+	var (
+		clock   = glock.NewMockClock()
+		breaker = NewCircuitBreaker(
+			testConfig(),
+			WithTripCondition(MinimumVolume(
+				2,
+				time.Minute,
+				NewRollingErrorPercentageTripCondition(time.Minute, 6, 0.5, 1),
+			)),
+			withClock(clock),
+		)
+	)
+
+	Expect(breaker.Call(errFunc)).To(Equal(testErr))
+	Expect(breaker.Call(errFunc)).To(Equal(testErr))
+	Expect(breaker.Call(nilFunc)).To(beError(ErrCircuitOpen))
+
+	breaker.Reset()
+	clock.Advance(time.Minute)
+	Expect(breaker.Call(nilFunc)).To(BeNil())
+}
+
+func (s *TripCompositeSuite) TestReportConfig(t sweet.T) {
+	collector := newTestCollector()
+
+	NewCircuitBreaker(
+		testConfig(),
+		WithCollector(collector),
+		WithTripCondition(MinimumVolume(
+			30,
+			time.Minute,
+			AnyOf(
+				NewConsecutiveFailureTripCondition(5),
+				NewRollingErrorPercentageTripCondition(10*time.Second, 10, 0.5, 20),
+			),
+		)),
+	)
+
+	Expect(collector.configs).To(ConsistOf(BreakerConfig{
+		MaxConcurrency:           100,
+		ErrorThresholdPercentage: 50,
+		RequestVolumeThreshold:   30,
+	}))
+}
+
+func (s *TripCompositeSuite) TestString(t sweet.T) {
+	var (
+		clock = glock.NewMockClock()
+		tc    = newMinimumVolumeWithClock(
+			10,
+			time.Minute,
+			AnyOf(
+				NewConsecutiveFailureTripCondition(5),
+				Negate(AllOf(NewConsecutiveFailureTripCondition(1))),
+			),
+			clock,
+		)
+	)
+
+	times(3, tc.Failure)
+	Expect(describeTripCondition(tc)).To(Equal(
+		"any of (3 of 5 consecutive failures; not (all of (3 of 1 consecutive failures))) (3 of 10 calls within 1m0s)",
+	))
+}
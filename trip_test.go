@@ -122,3 +122,188 @@ func times(n int, f func()) {
 		f()
 	}
 }
+
+func (s *TripSuite) TestString(t sweet.T) {
+	var (
+		clock       = glock.NewMockClock()
+		consecutive = NewConsecutiveFailureTripCondition(5)
+		window      = newWindowFailureTripConditionWithClock(time.Minute, 10, clock)
+		percentage  = NewPercentageFailureTripCondition(100, 0.5)
+	)
+
+	for i := 0; i < 3; i++ {
+		consecutive.Failure()
+		window.Failure()
+		percentage.Failure()
+		percentage.Success()
+	}
+
+	clock.Advance(time.Minute)
+	window.Failure()
+
+	Expect(describeTripCondition(consecutive)).To(Equal("3 of 5 consecutive failures"))
+	Expect(describeTripCondition(window)).To(Equal("1 of 10 failures within 1m0s"))
+	Expect(describeTripCondition(percentage)).To(Equal("3 failures of the last 6 of 100 calls (threshold 50%)"))
+}
+
+func (s *TripSuite) TestSlowCallRate(t sweet.T) {
+	tc := NewSlowCallRateTripCondition(time.Minute, time.Second, 0.5, 10).(DurationAwareTripCondition)
+
+	times(5, func() { tc.SuccessWithDuration(time.Millisecond) })
+	times(4, func() { tc.SuccessWithDuration(time.Second * 2) })
+	Expect(tc.ShouldTrip()).To(BeFalse())
+
+	tc.FailureWithDuration(time.Second)
+	Expect(tc.ShouldTrip()).To(BeTrue())
+
+	tc.SuccessWithDuration(time.Millisecond)
+	Expect(tc.ShouldTrip()).To(BeFalse())
+}
+
+func (s *TripSuite) TestSlowCallRateMinimumCalls(t sweet.T) {
+	tc := NewSlowCallRateTripCondition(time.Minute, time.Second, 0.5, 10).(DurationAwareTripCondition)
+
+	times(9, func() { tc.SuccessWithDuration(time.Minute) })
+	Expect(tc.ShouldTrip()).To(BeFalse())
+
+	tc.SuccessWithDuration(time.Minute)
+	Expect(tc.ShouldTrip()).To(BeTrue())
+}
+
+func (s *TripSuite) TestSlowCallRateUnknownDuration(t sweet.T) {
+	tc := NewSlowCallRateTripCondition(time.Minute, time.Second, 0.5, 1).(DurationAwareTripCondition)
+
+	tc.SuccessWithDuration(time.Minute)
+	Expect(tc.ShouldTrip()).To(BeTrue())
+
+	tc.Success()
+	tc.Failure()
+	Expect(tc.ShouldTrip()).To(BeFalse())
+}
+
+func (s *TripSuite) TestSlowCallRateWindow(t sweet.T) {
+	var (
+		clock = glock.NewMockClock()
+		tc    = newSlowCallRateTripConditionWithClock(
+			3*time.Second,
+			time.Second,
+			0.5,
+			4,
+			clock,
+		).(DurationAwareTripCondition)
+	)
+
+	times(4, func() { tc.SuccessWithDuration(time.Second) })
+	Expect(tc.ShouldTrip()).To(BeTrue())
+
+	clock.Advance(time.Second * 2)
+	times(4, func() { tc.SuccessWithDuration(time.Millisecond) })
+	Expect(tc.ShouldTrip()).To(BeTrue())
+
+	// Expire slow calls
+	clock.Advance(time.Second)
+	Expect(tc.ShouldTrip()).To(BeFalse())
+}
+
+func (s *TripSuite) TestSlowCallRateString(t sweet.T) {
+	var (
+		clock = glock.NewMockClock()
+		tc    = newSlowCallRateTripConditionWithClock(time.Minute, time.Second, 0.5, 10, clock).(DurationAwareTripCondition)
+	)
+
+	tc.SuccessWithDuration(time.Second)
+	clock.Advance(time.Minute)
+	tc.SuccessWithDuration(time.Second)
+	tc.SuccessWithDuration(time.Millisecond)
+
+	Expect(describeTripCondition(tc)).To(Equal("1 of 2 calls slower than 1s within 1m0s (threshold 50%)"))
+}
+
+func (s *TripSuite) TestRollingErrorPercentage(t sweet.T) {
+	tc := NewRollingErrorPercentageTripCondition(10*time.Second, 10, 0.5, 20)
+
+	times(9, tc.Failure)
+	times(10, tc.Success)
+	Expect(tc.ShouldTrip()).To(BeFalse())
+
+	tc.Failure()
+	Expect(tc.ShouldTrip()).To(BeTrue())
+
+	tc.Success()
+	Expect(tc.ShouldTrip()).To(BeFalse())
+}
+
+func (s *TripSuite) TestRollingErrorPercentageRequestVolume(t sweet.T) {
+	tc := NewRollingErrorPercentageTripCondition(10*time.Second, 10, 0.5, 20)
+
+	times(19, tc.Failure)
+	Expect(tc.ShouldTrip()).To(BeFalse())
+
+	tc.Failure()
+	Expect(tc.ShouldTrip()).To(BeTrue())
+}
+
+func (s *TripSuite) TestRollingErrorPercentageWindow(t sweet.T) {
+	var (
+		clock = glock.NewMockClock()
+		tc    = newRollingErrorPercentageTripConditionWithClock(
+			10*time.Second,
+			10,
+			0.5,
+			10,
+			clock,
+		)
+	)
+
+	// Align the clock with the start of a bucket
+	clock.Advance(clock.Now().Truncate(time.Second).Add(time.Second).Sub(clock.Now()))
+
+	times(10, tc.Failure)
+	Expect(tc.ShouldTrip()).To(BeTrue())
+
+	clock.Advance(5 * time.Second)
+	times(10, tc.Success)
+	Expect(tc.ShouldTrip()).To(BeTrue())
+
+	clock.Advance(4 * time.Second)
+	Expect(tc.ShouldTrip()).To(BeTrue())
+
+	// Expire the first bucket
+	clock.Advance(time.Second)
+	Expect(tc.ShouldTrip()).To(BeFalse())
+
+	// Expire the second bucket
+	clock.Advance(5 * time.Second)
+	Expect(tc.ShouldTrip()).To(BeFalse())
+	Expect(describeTripCondition(tc)).To(Equal("0 failures of 0 calls within 10s (threshold 50%, volume 10)"))
+}
+
+func (s *TripSuite) TestRollingErrorPercentageBucketReuse(t sweet.T) {
+	var (
+		clock = glock.NewMockClock()
+		tc    = newRollingErrorPercentageTripConditionWithClock(
+			time.Second,
+			2,
+			0.5,
+			1,
+			clock,
+		)
+	)
+
+	tc.Failure()
+	Expect(tc.ShouldTrip()).To(BeTrue())
+
+	// Same slot in the ring, later period
+	clock.Advance(time.Second)
+	tc.Success()
+	Expect(tc.ShouldTrip()).To(BeFalse())
+	Expect(describeTripCondition(tc)).To(Equal("0 failures of 1 calls within 1s (threshold 50%, volume 1)"))
+}
+
+func (s *TripSuite) TestRollingErrorPercentageZeroWindow(t sweet.T) {
+	tc := newRollingErrorPercentageTripConditionWithClock(0, 10, 0.5, 1, glock.NewMockClock())
+
+	Expect(tc.Failure).NotTo(Panic())
+	Expect(tc.ShouldTrip()).To(BeTrue())
+	Expect(describeTripCondition(tc)).To(Equal("1 failures of 1 calls within 10ns (threshold 50%, volume 1)"))
+}
@@ -0,0 +1,73 @@
+package overcurrent
+
+import "time"
+
+type (
+	// rollingWindow counts events within a rolling window of time. The window is
+	// divided into a ring of fixed-width buckets, and a bucket's counts fall out of
+	// the window all at once. Each bucket holds a pair of counters whose meaning is
+	// given by the owner of the window. A rolling window is not safe for concurrent
+	// use.
+	rollingWindow struct {
+		buckets     []windowBucket
+		window      time.Duration
+		bucketWidth time.Duration
+	}
+
+	windowBucket struct {
+		start  time.Time
+		counts [2]int
+	}
+)
+
+// newRollingWindow creates a rolling window divided into the given number of
+// buckets. The window is clamped so that each bucket is at least a nanosecond
+// wide.
+func newRollingWindow(window time.Duration, buckets int) rollingWindow {
+	if buckets < 1 {
+		buckets = 1
+	}
+
+	if window < time.Duration(buckets) {
+		window = time.Duration(buckets)
+	}
+
+	return rollingWindow{
+		buckets:     make([]windowBucket, buckets),
+		window:      window,
+		bucketWidth: window / time.Duration(buckets),
+	}
+}
+
+// add increments the given counter of the bucket for the given time.
+func (w *rollingWindow) add(now time.Time, counter int) {
+	w.currentBucket(now).counts[counter]++
+}
+
+// sums returns the totals of both counters over the buckets within the window.
+func (w *rollingWindow) sums(now time.Time) (first, second int) {
+	for _, bucket := range w.buckets {
+		if now.Sub(bucket.start) < w.window {
+			first += bucket.counts[0]
+			second += bucket.counts[1]
+		}
+	}
+
+	return first, second
+}
+
+// currentBucket returns the bucket for the given time, clearing it first if
+// it was last used for an earlier period.
+func (w *rollingWindow) currentBucket(now time.Time) *windowBucket {
+	var (
+		start  = now.Truncate(w.bucketWidth)
+		index  = int((start.UnixNano() / int64(w.bucketWidth)) % int64(len(w.buckets)))
+		bucket = &w.buckets[index]
+	)
+
+	if !bucket.start.Equal(start) {
+		*bucket = windowBucket{start: start}
+	}
+
+	return bucket
+}
@@ -0,0 +1,67 @@
+package overcurrent
+
+import (
+	"time"
+
+	"github.com/aphistic/sweet"
+	"github.com/efritz/glock"
+	. "github.com/onsi/gomega"
+)
+
+type WindowSuite struct{}
+
+func (s *WindowSuite) TestSums(t sweet.T) {
+	var (
+		clock  = glock.NewMockClock()
+		window = newRollingWindow(10*time.Second, 10)
+	)
+
+	window.add(clock.Now(), 0)
+	window.add(clock.Now(), 1)
+	clock.Advance(5 * time.Second)
+	window.add(clock.Now(), 1)
+
+	first, second := window.sums(clock.Now())
+	Expect(first).To(Equal(1))
+	Expect(second).To(Equal(2))
+
+	// The first bucket falls out of the window
+	clock.Advance(5 * time.Second)
+	first, second = window.sums(clock.Now())
+	Expect(first).To(Equal(0))
+	Expect(second).To(Equal(1))
+
+	clock.Advance(5 * time.Second)
+	first, second = window.sums(clock.Now())
+	Expect(first).To(Equal(0))
+	Expect(second).To(Equal(0))
+}
+
+func (s *WindowSuite) TestBucketReuse(t sweet.T) {
+	var (
+		clock  = glock.NewMockClock()
+		window = newRollingWindow(10*time.Second, 10)
+	)
+
+	window.add(clock.Now(), 0)
+	clock.Advance(10 * time.Second)
+	window.add(clock.Now(), 0)
+
+	first, _ := window.sums(clock.Now())
+	Expect(first).To(Equal(1))
+}
+
+func (s *WindowSuite) TestClamp(t sweet.T) {
+	clock := glock.NewMockClock()
+
+	for _, window := range []rollingWindow{
+		newRollingWindow(0, 10),
+		newRollingWindow(5*time.Nanosecond, 10),
+		newRollingWindow(-time.Second, 0),
+	} {
+		Expect(window.bucketWidth).To(BeNumerically(">", 0))
+		window.add(clock.Now(), 0)
+		first, _ := window.sums(clock.Now())
+		Expect(first).To(Equal(1))
+	}
+}